@@ -0,0 +1,29 @@
+package contractcall
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// IAccessListCreator creates an EIP-2930 access list for msg, e.g. with eth_createAccessList.
+type IAccessListCreator interface {
+	CreateAccessList(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (ethTypes.AccessList, error)
+}
+
+// AccessListFuncImpl adapts a function to IAccessListCreator.
+//
+//	creator := AccessListFuncImpl(func(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (ethTypes.AccessList, error) {
+//		res, err := client.CreateAccessList(ctx, msg, nil)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return res.AccessList.ToEthAccessList(), nil
+//	})
+type AccessListFuncImpl func(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (ethTypes.AccessList, error)
+
+func (i AccessListFuncImpl) CreateAccessList(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (ethTypes.AccessList, error) {
+	return i(ctx, chainId, msg)
+}
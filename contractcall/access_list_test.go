@@ -0,0 +1,64 @@
+package contractcall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTxBuilder_SetAccessListBy(t *testing.T) {
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	accessList := ethTypes.AccessList{{Address: to, StorageKeys: []common.Hash{{1}}}}
+	creator := AccessListFuncImpl(func(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (ethTypes.AccessList, error) {
+		return accessList, nil
+	})
+	estimator := func(with, without int64) IEstimateGas {
+		return GasEstimateFuncImpl(func(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*big.Int, error) {
+			if msg.AccessList != nil {
+				return big.NewInt(with), nil
+			}
+			return big.NewInt(without), nil
+		})
+	}
+	newBuilder := func(gasPrice *GasPrice) *TxBuilder {
+		return NewTxBuilder(t.Context(), big.NewInt(1)).
+			SetFrom(from).
+			SetTo(to, false).
+			SetNonce(0).
+			SetGasPrice(gasPrice)
+	}
+	legacy := NewGasPriceLegacy(big.NewInt(10))
+	dynamic := NewGasPrice(big.NewInt(1), big.NewInt(1), big.NewInt(10))
+
+	tests := []struct {
+		name     string
+		gasPrice *GasPrice
+		with     int64
+		without  int64
+		wantType uint8
+		wantGas  uint64
+		wantList bool
+	}{
+		{name: "legacy cheaper with list", gasPrice: legacy, with: 50_000, without: 52_000, wantType: ethTypes.AccessListTxType, wantGas: 50_000, wantList: true},
+		{name: "legacy cheaper without list", gasPrice: legacy, with: 53_000, without: 52_000, wantType: ethTypes.LegacyTxType, wantGas: 52_000},
+		{name: "dynamic cheaper with list", gasPrice: dynamic, with: 50_000, without: 52_000, wantType: ethTypes.DynamicFeeTxType, wantGas: 50_000, wantList: true},
+		{name: "dynamic cheaper without list", gasPrice: dynamic, with: 52_000, without: 52_000, wantType: ethTypes.DynamicFeeTxType, wantGas: 52_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txWrapper, err := newBuilder(tt.gasPrice).SetAccessListBy(creator, estimator(tt.with, tt.without)).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx := txWrapper.ToTransaction()
+			if tx.Type() != tt.wantType || tx.Gas() != tt.wantGas || (len(tx.AccessList()) > 0) != tt.wantList {
+				t.Fatalf("got type=%d gas=%d list=%v", tx.Type(), tx.Gas(), tx.AccessList())
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+var ErrAuthorizationNotSigned = errors.New("signer returned no signature for the authorization")
+
+// SignAuthorization signs an EIP-7702 authorization delegating the code of signer's account to delegate.
+// chainId 0 makes the authorization valid on every chain, nonce is the authority's nonce at the time
+// the authorization is processed, see AuthorizationNonce.
+func SignAuthorization(signer ISigner, chainId *big.Int, delegate common.Address, nonce uint64) (ethTypes.SetCodeAuthorization, error) {
+	auth := ethTypes.SetCodeAuthorization{
+		ChainID: *newInt(chainId),
+		Address: delegate,
+		Nonce:   nonce,
+	}
+	sig, err := signer.Sign(auth.SigHash().Bytes())
+	if err != nil {
+		return auth, err
+	}
+	if sig == nil {
+		return auth, ErrAuthorizationNotSigned
+	}
+	v := sig.V()
+	if v >= 27 {
+		v -= 27
+	}
+	auth.V = v
+	auth.R.SetFromBig(sig.R())
+	auth.S.SetFromBig(sig.S())
+	return auth, nil
+}
+
+// RecoverAuthority returns the account that signed auth.
+func RecoverAuthority(auth ethTypes.SetCodeAuthorization) (common.Address, error) {
+	return auth.Authority()
+}
+
+// RevokeAuthorization signs an authorization that clears the delegation of signer's account.
+func RevokeAuthorization(signer ISigner, chainId *big.Int, nonce uint64) (ethTypes.SetCodeAuthorization, error) {
+	return SignAuthorization(signer, chainId, common.Address{}, nonce)
+}
+
+// AuthorizationNonce returns the nonce authority must sign into its next authorization of a
+// set-code transaction sent by sender with txNonce.
+//
+// The sender's nonce is incremented before the authorization list is processed, so an authority
+// that also sends the transaction signs txNonce+1. Every earlier authorization of the same
+// authority in authList increments its nonce once more. The pending nonce of the authority is
+// read from nonces when it is not the sender, a nonce manager is not used so no nonce is allocated.
+func AuthorizationNonce(ctx context.Context, nonces IPendingNonceAt, sender common.Address, txNonce uint64, authority common.Address, authList []ethTypes.SetCodeAuthorization) (uint64, error) {
+	var nonce uint64
+	if authority == sender {
+		nonce = txNonce + 1
+	} else {
+		if nonces == nil {
+			return 0, errors.New("a nonce source is required when the authority is not the sender")
+		}
+		n, err := nonces.PendingNonceAt(ctx, authority)
+		if err != nil {
+			return 0, err
+		}
+		nonce = n
+	}
+	for _, auth := range authList {
+		if signer, err := auth.Authority(); err == nil && signer == authority {
+			nonce++
+		}
+	}
+	return nonce, nil
+}
@@ -0,0 +1,101 @@
+package contractcall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+type fixedNonce uint64
+
+func (f fixedNonce) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return uint64(f), nil
+}
+
+func TestSignAuthorization(t *testing.T) {
+	signer := newTestSigner(t)
+	delegate := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+
+	auth, err := SignAuthorization(signer, big.NewInt(1), delegate, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.V > 1 || auth.Nonce != 7 || auth.Address != delegate || auth.ChainID.Uint64() != 1 {
+		t.Fatalf("unexpected authorization %+v", auth)
+	}
+	authority, err := RecoverAuthority(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authority != signer.Address() {
+		t.Fatalf("expected authority %s, got %s", signer.Address(), authority)
+	}
+
+	if _, err := SignAuthorization(NewNoOpSigner(signer.Address(), nil), big.NewInt(1), delegate, 7); err != ErrAuthorizationNotSigned {
+		t.Fatalf("expected ErrAuthorizationNotSigned, got %v", err)
+	}
+}
+
+func TestAuthorizationNonce(t *testing.T) {
+	sender := newTestSigner(t)
+	other := newTestSigner(t)
+	ctx := t.Context()
+
+	nonce, err := AuthorizationNonce(ctx, nil, sender.Address(), 5, sender.Address(), nil)
+	if err != nil || nonce != 6 {
+		t.Fatalf("sender as authority: expected 6, got %d (%v)", nonce, err)
+	}
+	nonce, err = AuthorizationNonce(ctx, fixedNonce(2), sender.Address(), 5, other.Address(), nil)
+	if err != nil || nonce != 2 {
+		t.Fatalf("other authority: expected 2, got %d (%v)", nonce, err)
+	}
+
+	first, _ := SignAuthorization(sender, big.NewInt(1), common.Address{1}, 6)
+	nonce, err = AuthorizationNonce(ctx, nil, sender.Address(), 5, sender.Address(), []ethTypes.SetCodeAuthorization{first})
+	if err != nil || nonce != 7 {
+		t.Fatalf("second authorization: expected 7, got %d (%v)", nonce, err)
+	}
+}
+
+func TestTxBuilder_SetCode(t *testing.T) {
+	signer := newTestSigner(t)
+	delegate := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	self := signer.Address()
+
+	txWrapper, err := NewTxBuilder(t.Context(), big.NewInt(1)).
+		SetFrom(self).
+		SetTo(self, false).
+		SetNonce(3).
+		SetGasPrice(NewGasPrice(big.NewInt(1), big.NewInt(1), big.NewInt(2))).
+		SetGasLimit(100_000).
+		AddAuthorization(signer, delegate, nil).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txWrapper.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	tx := txWrapper.ToTransaction()
+	if tx.Type() != ethTypes.SetCodeTxType || len(tx.SetCodeAuthorizations()) != 1 {
+		t.Fatalf("expected a set-code transaction, got type %d", tx.Type())
+	}
+	auth := tx.SetCodeAuthorizations()[0]
+	authority, err := auth.Authority()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authority != self || auth.Nonce != 4 || auth.Address != delegate {
+		t.Fatalf("unexpected authorization %+v", auth)
+	}
+	sender, err := ethTypes.Sender(ethTypes.LatestSignerForChainID(big.NewInt(1)), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != self {
+		t.Fatalf("expected sender %s, got %s", self, sender)
+	}
+}
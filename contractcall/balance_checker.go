@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -11,15 +12,34 @@ type IBalanceChecker interface {
 	CheckBalance(ctx context.Context, from common.Address, data []byte, to *common.Address, gasPrice *GasPrice) error
 }
 
+// IBlobBalanceChecker is implemented by balance checkers that can account for the blob fee of blob transactions.
+type IBlobBalanceChecker interface {
+	CheckBalanceWithBlobFee(ctx context.Context, from common.Address, data []byte, to *common.Address, gasPrice *GasPrice, blobFee *big.Int) error
+}
+
+var _ IBlobBalanceChecker = &BalanceCheckerImpl{}
+
 type BalanceCheckerImpl struct {
-	client IBalance
+	client    IBalance
+	chainId   *big.Int
+	l1DataFee IL1DataFee
 }
 
 func NewBalanceCheckerImpl(client IBalance) *BalanceCheckerImpl {
 	return &BalanceCheckerImpl{client: client}
 }
 
+// NewBalanceCheckerImplL2 also requires the L1 data fee of the transaction, see OpGasEstimateImpl and ArbGasEstimateImpl.
+func NewBalanceCheckerImplL2(client IBalance, chainId *big.Int, l1DataFee IL1DataFee) *BalanceCheckerImpl {
+	return &BalanceCheckerImpl{client: client, chainId: chainId, l1DataFee: l1DataFee}
+}
+
 func (b *BalanceCheckerImpl) CheckBalance(ctx context.Context, from common.Address, data []byte, to *common.Address, gasPrice *GasPrice) error {
+	return b.CheckBalanceWithBlobFee(ctx, from, data, to, gasPrice, nil)
+}
+
+// CheckBalanceWithBlobFee is CheckBalance for blob transactions, blobFee is blob gas * max fee per blob gas.
+func (b *BalanceCheckerImpl) CheckBalanceWithBlobFee(ctx context.Context, from common.Address, data []byte, to *common.Address, gasPrice *GasPrice, blobFee *big.Int) error {
 	balance, err := b.client.BalanceAt(ctx, from, nil)
 	if err != nil {
 		return err
@@ -29,27 +49,42 @@ func (b *BalanceCheckerImpl) CheckBalance(ctx context.Context, from common.Addre
 	}
 	var IsIstanbul = true
 	var IsShanghai = true
+	var maxGas = new(big.Int)
 	intrGas, err := IntrinsicGas(data, nil, nil, to == nil, true, IsIstanbul, IsShanghai)
 	if err != nil {
 		// overflow uint64
 	} else {
 		if gasPrice.LegacyGas != nil {
-			var maxGas = new(big.Int).Mul(
+			maxGas = new(big.Int).Mul(
 				new(big.Int).SetUint64(intrGas), /*gas limit*/
 				gasPrice.LegacyGas.GasPrice,
 			)
-			if maxGas.Cmp(balance) == 1 {
-				return &InsufficientBalanceError{Balance: balance}
-			}
 		} else if gasPrice.DynamicGas != nil {
-			var maxGas = new(big.Int).Mul(
+			maxGas = new(big.Int).Mul(
 				new(big.Int).SetUint64(intrGas), /*gas limit*/
 				gasPrice.DynamicGas.MaxFeePerGas,
 			)
-			if maxGas.Cmp(balance) == 1 {
-				return &InsufficientBalanceError{Balance: balance}
-			}
 		}
 	}
+	if b.l1DataFee != nil {
+		msg := ethereum.CallMsg{From: from, To: to, Data: data, Gas: intrGas}
+		if gasPrice.LegacyGas != nil {
+			msg.GasPrice = gasPrice.LegacyGas.GasPrice
+		} else if gasPrice.DynamicGas != nil {
+			msg.GasTipCap = gasPrice.DynamicGas.MaxPriorityFeePerGas
+			msg.GasFeeCap = gasPrice.DynamicGas.MaxFeePerGas
+		}
+		l1Fee, err := b.l1DataFee.L1DataFee(ctx, b.chainId, msg)
+		if err != nil {
+			return err
+		}
+		maxGas.Add(maxGas, l1Fee)
+	}
+	if blobFee != nil {
+		maxGas.Add(maxGas, blobFee)
+	}
+	if maxGas.Cmp(balance) == 1 {
+		return &InsufficientBalanceError{Balance: balance}
+	}
 	return nil
 }
@@ -0,0 +1,87 @@
+package contractcall
+
+import (
+	"context"
+	"math/big"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BlobBytesPerFieldElement is the payload EncodeBlobs stores in each 32-byte field element,
+// the first byte is left zero so every element stays below the BLS modulus.
+const BlobBytesPerFieldElement = 31
+
+// BlobDataCapacity is the payload size of one blob written by EncodeBlobs.
+const BlobDataCapacity = BlobBytesPerFieldElement * params.BlobTxFieldElementsPerBlob
+
+// DefaultBlobSidecarVersion is the sidecar version used by TxBuilder.SetBlobs.
+// Version 1 carries cell proofs and is required since the Osaka fork,
+// use ethTypes.BlobSidecarVersion0 for chains that haven't activated it.
+var DefaultBlobSidecarVersion = ethTypes.BlobSidecarVersion1
+
+type IBlobBaseFee interface {
+	BlobBaseFee(ctx context.Context) (*big.Int, error)
+}
+
+// EncodeBlobs packs data into as many blobs as needed, 31 bytes per field element.
+// The last blob is zero padded, the data length must be stored separately if it matters.
+func EncodeBlobs(data []byte) []kzg4844.Blob {
+	count := (len(data) + BlobDataCapacity - 1) / BlobDataCapacity
+	if count == 0 {
+		count = 1
+	}
+	blobs := make([]kzg4844.Blob, count)
+	for i := 0; len(data) > 0; i++ {
+		blob := &blobs[i/params.BlobTxFieldElementsPerBlob]
+		offset := (i%params.BlobTxFieldElementsPerBlob)*32 + 1
+		n := copy(blob[offset:offset+BlobBytesPerFieldElement], data)
+		data = data[n:]
+	}
+	return blobs
+}
+
+// DecodeBlobs reverses EncodeBlobs, the result includes the zero padding of the last blob.
+func DecodeBlobs(blobs []kzg4844.Blob) []byte {
+	data := make([]byte, 0, len(blobs)*BlobDataCapacity)
+	for i := range blobs {
+		for j := 0; j < params.BlobTxFieldElementsPerBlob; j++ {
+			data = append(data, blobs[i][j*32+1:(j+1)*32]...)
+		}
+	}
+	return data
+}
+
+// NewBlobSidecar computes the KZG commitments and proofs of blobs.
+// Version 0 sidecars carry one blob proof per blob, version 1 sidecars carry cell proofs.
+func NewBlobSidecar(version byte, blobs []kzg4844.Blob) (*ethTypes.BlobTxSidecar, error) {
+	commitments := make([]kzg4844.Commitment, len(blobs))
+	var proofs []kzg4844.Proof
+	for i := range blobs {
+		commitment, err := kzg4844.BlobToCommitment(&blobs[i])
+		if err != nil {
+			return nil, err
+		}
+		commitments[i] = commitment
+		if version == ethTypes.BlobSidecarVersion0 {
+			proof, err := kzg4844.ComputeBlobProof(&blobs[i], commitment)
+			if err != nil {
+				return nil, err
+			}
+			proofs = append(proofs, proof)
+		} else {
+			cellProofs, err := kzg4844.ComputeCellProofs(&blobs[i])
+			if err != nil {
+				return nil, err
+			}
+			proofs = append(proofs, cellProofs...)
+		}
+	}
+	return ethTypes.NewBlobTxSidecar(version, blobs, commitments, proofs), nil
+}
+
+// BlobGas returns the blob gas used by a transaction carrying blobCount blobs.
+func BlobGas(blobCount int) uint64 {
+	return uint64(blobCount) * params.BlobTxBlobGasPerBlob
+}
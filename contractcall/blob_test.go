@@ -0,0 +1,121 @@
+package contractcall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+type fixedBlobBaseFee int64
+
+func (f fixedBlobBaseFee) BlobBaseFee(context.Context) (*big.Int, error) {
+	return big.NewInt(int64(f)), nil
+}
+
+type fixedBalance int64
+
+func (f fixedBalance) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	return big.NewInt(int64(f)), nil
+}
+
+func TestEncodeBlobs(t *testing.T) {
+	data := bytes.Repeat([]byte{0xff, 0x01, 0x02}, BlobDataCapacity/3+10)
+	blobs := EncodeBlobs(data)
+	if len(blobs) != 2 {
+		t.Fatalf("expected 2 blobs, got %d", len(blobs))
+	}
+	for i := range blobs {
+		for j := 0; j < len(blobs[i]); j += 32 {
+			if blobs[i][j] != 0 {
+				t.Fatalf("blob %d field element %d is not canonical", i, j/32)
+			}
+		}
+	}
+	decoded := DecodeBlobs(blobs)
+	if !bytes.Equal(decoded[:len(data)], data) || len(bytes.Trim(decoded[len(data):], "\x00")) != 0 {
+		t.Fatal("decoded data doesn't match")
+	}
+}
+
+func TestTxBuilder_Blob(t *testing.T) {
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	gasPrice := NewGasPrice(big.NewInt(10), big.NewInt(1), big.NewInt(20))
+
+	builder := NewTxBuilder(t.Context(), big.NewInt(1)).
+		SetFrom(from).
+		SetTo(to, false).
+		SetNonce(4).
+		SetGasPrice(gasPrice).
+		SetGasLimit(21000).
+		SetBlobData([]byte("rollup batch")).
+		SetMaxFeePerBlobGasBy(fixedBlobBaseFee(3))
+	txWrapper, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := txWrapper.ToTransaction()
+	if tx.Type() != ethTypes.BlobTxType || tx.BlobGasFeeCap().Int64() != 6 || tx.BlobGas() != BlobGas(1) {
+		t.Fatalf("unexpected blob tx type=%d blobFeeCap=%s blobGas=%d", tx.Type(), tx.BlobGasFeeCap(), tx.BlobGas())
+	}
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil || sidecar.Version != DefaultBlobSidecarVersion {
+		t.Fatal("sidecar is missing")
+	}
+	if err := sidecar.ValidateBlobCommitmentHashes(tx.BlobHashes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := kzg4844.VerifyCellProofs(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+		t.Fatal(err)
+	}
+
+	// 21000*20 = 420000 fits, the blob fee of 131072*6 doesn't
+	var insufficient *InsufficientBalanceError
+	if err := builder.BalanceCheck(NewBalanceCheckerImpl(fixedBalance(500_000))).Error(); !errors.As(err, &insufficient) {
+		t.Fatalf("expected insufficient balance for the blob fee, got %v", err)
+	}
+
+	_, err = NewTxBuilder(t.Context(), big.NewInt(1)).
+		SetFrom(from).
+		SetTo(to, false).
+		SetNonce(4).
+		SetGasPrice(gasPrice).
+		SetGasLimit(21000).
+		SetBlobData([]byte("rollup batch")).
+		Build()
+	if !errors.Is(err, TxBuilderMissingRequiredFieldErr) {
+		t.Fatalf("expected missing max fee per blob gas, got %v", err)
+	}
+
+	// blob transactions can't create contracts
+	_, err = NewTxBuilder(t.Context(), big.NewInt(1)).
+		SetFrom(from).
+		SetNonce(4).
+		SetGasPrice(gasPrice).
+		SetGasLimit(21000).
+		SetBlobData([]byte("rollup batch")).
+		SetMaxFeePerBlobGas(big.NewInt(6)).
+		Build()
+	if !errors.Is(err, TxBuilderMissingRequiredFieldErr) {
+		t.Fatalf("expected missing recipient, got %v", err)
+	}
+}
+
+func TestNewBlobSidecar_V0(t *testing.T) {
+	sidecar, err := NewBlobSidecar(ethTypes.BlobSidecarVersion0, EncodeBlobs([]byte("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sidecar.Proofs) != 1 {
+		t.Fatalf("expected 1 blob proof, got %d", len(sidecar.Proofs))
+	}
+	if err := kzg4844.VerifyBlobProof(&sidecar.Blobs[0], sidecar.Commitments[0], sidecar.Proofs[0]); err != nil {
+		t.Fatal(err)
+	}
+}
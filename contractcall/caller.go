@@ -82,6 +82,8 @@ func EstimateTxE(
 	if err != nil {
 		return nil, nil, err
 	}
+	// nothing is sent, hand the nonce back
+	builder.releaseNonce(nil)
 	return builder.gasPrice, builder.gasLimit, builder.err
 }
 
@@ -95,18 +97,22 @@ func SendTxBuilder(
 ) (*ethTypes.Transaction, error) {
 	txWrapper, err := txBuilder.SetFrom(payer.Address()).Build()
 	if err != nil {
+		txBuilder.releaseNonce(nil)
 		return nil, err
 	}
 	if err = txWrapper.Sign(payer); err != nil {
+		txBuilder.releaseNonce(nil)
 		return nil, err
 	}
 	tx := txWrapper.ToTransaction()
 	if beforeSend != nil {
 		if err = beforeSend(tx); err != nil {
+			txBuilder.releaseNonce(nil)
 			return nil, err
 		}
 	}
 	if noSend {
+		// the caller broadcasts the transaction, the nonce stays handed out
 		return tx, nil
 	}
 	err = client.SendTransaction(ctx, tx)
@@ -115,8 +121,13 @@ func SendTxBuilder(
 			Tx:  tx,
 			Err: ParseEvmError(err),
 		}
+		if txBuilder.settleNonce(errSend) {
+			// the node already has this very transaction
+			return tx, nil
+		}
 		return nil, fmt.Errorf("ethereum send transaction failed: %w,%w", errSend, EthereumRPCErr)
 	}
+	txBuilder.confirmNonce()
 	return tx, nil
 }
 
@@ -166,6 +177,7 @@ func sendFn[T any](
 		SetGasLimitBy(callManager.GasEstimate).
 		Check(client, callManager.GasValidator, opt...)
 	if txBuilder.err != nil {
+		txBuilder.releaseNonce(nil)
 		return *new(T), txBuilder.err
 	}
 	return fn(txBuilder)
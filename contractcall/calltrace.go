@@ -0,0 +1,241 @@
+package contractcall
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethclient"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TraceDecoder decodes callTracer frames against a registry of known ABIs.
+// ABIs registered for an address take precedence over the global ones.
+type TraceDecoder struct {
+	abis      []*abi.ABI
+	byAddress map[common.Address]*abi.ABI
+	labels    map[common.Address]string
+}
+
+// NewTraceDecoder returns a decoder that looks up selectors in abis.
+func NewTraceDecoder(abis ...*abi.ABI) *TraceDecoder {
+	return &TraceDecoder{
+		abis:      abis,
+		byAddress: make(map[common.Address]*abi.ABI),
+		labels:    make(map[common.Address]string),
+	}
+}
+
+// Register adds an ABI to the global registry.
+func (d *TraceDecoder) Register(a *abi.ABI) *TraceDecoder {
+	d.abis = append(d.abis, a)
+	return d
+}
+
+// RegisterContract binds an ABI and a label (e.g. "USDC", may be empty) to an address.
+func (d *TraceDecoder) RegisterContract(address common.Address, label string, a *abi.ABI) *TraceDecoder {
+	if a != nil {
+		d.byAddress[address] = a
+	}
+	if label != "" {
+		d.labels[address] = label
+	}
+	return d
+}
+
+// DecodedCall is a call frame with its decoded input, output and revert reason.
+type DecodedCall struct {
+	Frame *ethclient.CallFrame
+	Depth int
+	// Method is nil if the selector is unknown or the input could not be decoded
+	Method  *abi.Method
+	Args    []any
+	Returns []any
+	// Revert is the decoded revert data of a failed frame, nil if unknown
+	Revert *KnownMethodError
+	Calls  []*DecodedCall
+
+	labels map[common.Address]string
+}
+
+// Decode decodes frame and all its sub calls.
+func (d *TraceDecoder) Decode(frame *ethclient.CallFrame) *DecodedCall {
+	return d.decode(frame, 0)
+}
+
+// TraceTransaction fetches the call tree of a transaction with the callTracer and decodes it.
+func (d *TraceDecoder) TraceTransaction(ctx context.Context, ec *ethclient.Client, txHash common.Hash) (*DecodedCall, error) {
+	frame, err := ec.CallTraceTransaction(ctx, ecommon.Hash(txHash), ethclient.CallTracerConfig{})
+	if err != nil {
+		return nil, err
+	}
+	return d.Decode(frame), nil
+}
+
+func (d *TraceDecoder) decode(frame *ethclient.CallFrame, depth int) *DecodedCall {
+	call := &DecodedCall{Frame: frame, Depth: depth, labels: d.labels}
+	if method := d.method(frame); method != nil {
+		if args, err := method.Inputs.Unpack(frame.Input[4:]); err == nil {
+			call.Method, call.Args = method, args
+			if !frame.Failed() && len(frame.Output) > 0 {
+				call.Returns, _ = method.Outputs.Unpack(frame.Output)
+			}
+		}
+	}
+	if frame.Failed() && len(frame.Output) > 0 {
+		call.Revert = ParseRevertedData(d.candidates(frame.To), frame.Output)
+	}
+	call.Calls = make([]*DecodedCall, len(frame.Calls))
+	for i := range frame.Calls {
+		call.Calls[i] = d.decode(&frame.Calls[i], depth+1)
+	}
+	return call
+}
+
+// method finds the method called by frame, contract creations have no method.
+func (d *TraceDecoder) method(frame *ethclient.CallFrame) *abi.Method {
+	if frame.To == nil || len(frame.Input) < 4 || strings.HasPrefix(frame.Type, "CREATE") {
+		return nil
+	}
+	for _, a := range d.candidates(frame.To) {
+		if m, err := a.MethodById(frame.Input[:4]); err == nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// candidates returns the ABIs to search for a call to address, the bound one first.
+func (d *TraceDecoder) candidates(address *ecommon.Address) []*abi.ABI {
+	if address != nil {
+		if a, ok := d.byAddress[common.Address(*address)]; ok {
+			return append([]*abi.ABI{a}, d.abis...)
+		}
+	}
+	return d.abis
+}
+
+// Walk visits c and its sub calls depth first.
+func (c *DecodedCall) Walk(fn func(call *DecodedCall)) {
+	fn(c)
+	for _, sub := range c.Calls {
+		sub.Walk(fn)
+	}
+}
+
+// Signature returns the decoded call, e.g. "transfer(to=0x..., value=1)", or the raw selector if unknown.
+func (c *DecodedCall) Signature() string {
+	if c.Method == nil {
+		switch {
+		case strings.HasPrefix(c.Frame.Type, "CREATE"):
+			return fmt.Sprintf("<create %d bytes>", len(c.Frame.Input))
+		case len(c.Frame.Input) == 0:
+			return "<fallback>"
+		case len(c.Frame.Input) < 4:
+			return fmt.Sprintf("0x%x", c.Frame.Input)
+		default:
+			return fmt.Sprintf("0x%x(<%d bytes>)", c.Frame.Input[:4], len(c.Frame.Input)-4)
+		}
+	}
+	return c.Method.RawName + "(" + formatArguments(c.Method.Inputs, c.Args) + ")"
+}
+
+// Render writes c as an indented tree, one line per call, using the labels of the decoder:
+//
+//	CALL 0xA -> USDC.transfer(to=0xB, value=1) => (true) [gas 21000]
+//	└─ DELEGATECALL USDC -> 0xC.transfer(to=0xB, value=1) [gas 9000]
+func (c *DecodedCall) Render(w io.Writer) error {
+	return c.render(w, "", "")
+}
+
+func (c *DecodedCall) render(w io.Writer, prefix, childPrefix string) error {
+	if _, err := io.WriteString(w, prefix+c.line()+"\n"); err != nil {
+		return err
+	}
+	for i, sub := range c.Calls {
+		branch, next := "├─ ", "│  "
+		if i == len(c.Calls)-1 {
+			branch, next = "└─ ", "   "
+		}
+		if err := sub.render(w, childPrefix+branch, childPrefix+next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *DecodedCall) line() string {
+	f, labels := c.Frame, c.labels
+	var b strings.Builder
+	b.WriteString(f.Type + " " + label(labels, common.Address(f.From)) + " -> ")
+	if f.To != nil {
+		b.WriteString(label(labels, common.Address(*f.To)) + ".")
+	}
+	b.WriteString(c.Signature())
+	if f.Value != nil && f.Value.Sign() > 0 {
+		b.WriteString(" value=" + formatEther(f.Value))
+	}
+	switch {
+	case f.Failed():
+		b.WriteString(" !! " + f.Error)
+		if c.Revert != nil {
+			b.WriteString(": " + c.Revert.Formatted)
+		} else if f.RevertReason != "" {
+			b.WriteString(": " + f.RevertReason)
+		}
+	case c.Returns != nil:
+		b.WriteString(" => (" + formatArguments(c.Method.Outputs, c.Returns) + ")")
+	}
+	fmt.Fprintf(&b, " [gas %d]", f.GasUsed)
+	return b.String()
+}
+
+func (c *DecodedCall) String() string {
+	var b strings.Builder
+	_ = c.Render(&b)
+	return b.String()
+}
+
+func label(labels map[common.Address]string, address common.Address) string {
+	if l, ok := labels[address]; ok {
+		return l
+	}
+	return address.Hex()
+}
+
+func formatArguments(arguments abi.Arguments, values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		s := formatValue(v)
+		if i < len(arguments) && arguments[i].Name != "" {
+			s = arguments[i].Name + "=" + s
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case common.Address:
+		return v.Hex()
+	case []byte:
+		return fmt.Sprintf("0x%x", v)
+	case [32]byte:
+		return fmt.Sprintf("0x%x", v)
+	case *big.Int:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func formatEther(wei *big.Int) string {
+	f := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether))
+	return f.Text('f', -1) + " ETH"
+}
@@ -0,0 +1,51 @@
+package contractcall
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/donutnomad/eths/contracts_pack"
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/samber/lo"
+)
+
+func TestTraceDecoder(t *testing.T) {
+	erc20 := lo.Must1(contracts_pack.ERC20MetaData.ParseABI())
+	from, token, to := ecommon.Address{19: 1}, ecommon.Address{19: 2}, ecommon.Address{19: 3}
+
+	input := lo.Must1(erc20.Pack("transfer", common.Address(to), big.NewInt(100)))
+	output := lo.Must1(erc20.Methods["transfer"].Outputs.Pack(true))
+	revert := lo.Must1(erc20.Errors["ERC20InsufficientBalance"].Inputs.Pack(common.Address(token), big.NewInt(1), big.NewInt(100)))
+	revert = append(erc20.Errors["ERC20InsufficientBalance"].ID.Bytes()[:4:4], revert...)
+
+	frame := &ethclient.CallFrame{
+		Type: "CALL", From: from, To: &token, Input: input, Output: output, GasUsed: 30000, Value: big.NewInt(5e17),
+		Calls: []ethclient.CallFrame{
+			{Type: "CALL", From: token, To: &to, Input: []byte{1, 2, 3, 4, 5}, GasUsed: 100},
+			{Type: "CALL", From: token, To: &to, Input: input, Output: revert, Error: "execution reverted", GasUsed: 200},
+		},
+	}
+	call := NewTraceDecoder(erc20).RegisterContract(common.Address(token), "TOKEN", nil).Decode(frame)
+
+	if call.Method == nil || call.Method.Name != "transfer" || len(call.Returns) != 1 || call.Returns[0] != true {
+		t.Fatalf("unexpected top call %+v", call)
+	}
+	if call.Calls[0].Method != nil || call.Calls[1].Depth != 1 {
+		t.Fatalf("unexpected sub calls %+v", call.Calls)
+	}
+	if call.Calls[1].Revert == nil || call.Calls[1].Revert.Name != "ERC20InsufficientBalance" {
+		t.Fatalf("unexpected revert %+v", call.Calls[1].Revert)
+	}
+
+	want := []string{
+		"CALL 0x0000000000000000000000000000000000000001 -> TOKEN.transfer(to=0x0000000000000000000000000000000000000003, value=100) value=0.5 ETH => (true) [gas 30000]",
+		"├─ CALL TOKEN -> 0x0000000000000000000000000000000000000003.0x01020304(<1 bytes>) [gas 100]",
+		"└─ CALL TOKEN -> 0x0000000000000000000000000000000000000003.transfer(to=0x0000000000000000000000000000000000000003, value=100) !! execution reverted: ERC20InsufficientBalance(sender=0x0000000000000000000000000000000000000002,balance=1,needed=100) [gas 200]",
+	}
+	if got := call.String(); got != strings.Join(want, "\n")+"\n" {
+		t.Fatalf("unexpected rendering:\n%s", got)
+	}
+}
@@ -16,6 +16,7 @@ import (
 var EthereumRPCErr = errors.New("ethereum rpc error")
 var GasInvalidGasPriceErr = errors.New("invalid gas price")
 var TxBuilderMissingRequiredFieldErr = errors.New("missing required field")
+var ErrSignerModifiedTx = errors.New("signer returned a transaction that differs from the one it was asked to sign")
 var ErrContractCallEmptyData = errors.New("call contract with empty data is not allowed. if you confirm the contract is payable, please set strict to false")
 
 // SendTransactionError Ethereum SendTransaction Error
@@ -161,6 +162,28 @@ func (e *EvmError) IsNonceTooHigh() bool {
 	return strings.Contains(msg, "nonce too high")
 }
 
+// IsReplacementUnderpriced
+// geth: replacement transaction underpriced
+// reth: replacement transaction underpriced
+func (e *EvmError) IsReplacementUnderpriced() bool {
+	msg := e.Message
+	return strings.Contains(msg, "replacement transaction underpriced")
+}
+
+// IsAlreadyKnown the same transaction is already in the pool
+// geth: already known
+// reth: already known
+func (e *EvmError) IsAlreadyKnown() bool {
+	msg := e.Message
+	return strings.Contains(msg, "already known")
+}
+
+// IsRejected reports whether the node answered with a JSON-RPC error, i.e. it received the
+// request and refused it. After any other error (timeout, transport failure) the outcome is unknown.
+func (e *EvmError) IsRejected() bool {
+	return e.Message != "" || e.ErrCode != 0
+}
+
 func (e *EvmError) IsInsufficientBalance() bool {
 	msg := e.Message
 	// geth and reth
@@ -352,3 +375,24 @@ func buildKnownMethodError(errEvent *abi.Error, args []any) *KnownMethodError {
 		Formatted:  errEvent.Name + "(" + strings.Join(namedKeyValues, ",") + ")",
 	}
 }
+
+func asEvmError(err error) (*EvmError, bool) {
+	var evmErr *EvmError
+	ok := errors.As(err, &evmErr)
+	return evmErr, ok
+}
+
+func isAlreadyKnown(err error) bool {
+	evmErr, ok := asEvmError(err)
+	return ok && evmErr.IsAlreadyKnown()
+}
+
+func isReplacementUnderpriced(err error) bool {
+	evmErr, ok := asEvmError(err)
+	return ok && evmErr.IsReplacementUnderpriced()
+}
+
+func isRejected(err error) bool {
+	evmErr, ok := asEvmError(err)
+	return ok && evmErr.IsRejected()
+}
@@ -0,0 +1,159 @@
+package contractcall
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+var ErrUnknownEvent = errors.New("unknown event")
+
+// EventDecoder decodes logs of many contracts by topic0. Events are decoded into a name/argument
+// map, and into the typed value of a binding if one is registered for the event. Register all
+// events before decoding, Decode is safe for concurrent use.
+type EventDecoder struct {
+	byTopic   map[common.Hash][]*eventEntry
+	anonymous []*eventEntry
+}
+
+type eventEntry struct {
+	event   abi.Event
+	indexed abi.Arguments
+	// unpack returns the typed value of a binding, nil for generic decoding
+	unpack func(log *ethTypes.Log) (any, error)
+}
+
+// NewEventDecoder returns a decoder of the events of abis.
+func NewEventDecoder(abis ...*abi.ABI) *EventDecoder {
+	d := &EventDecoder{byTopic: make(map[common.Hash][]*eventEntry)}
+	for _, a := range abis {
+		d.Register(a)
+	}
+	return d
+}
+
+// Register adds the events of an ABI.
+func (d *EventDecoder) Register(a *abi.ABI) *EventDecoder {
+	for _, event := range a.Events {
+		d.add(event, nil)
+	}
+	return d
+}
+
+// RegisterBinding adds the events of an abigen v2 binding, unpack is its UnpackEvent method or
+// one of its UnpackXxxEvent methods:
+//
+//	RegisterBinding(d, &contracts_pack.ERC20MetaData, contracts_pack.NewERC20().UnpackEvent)
+//
+// Logs that unpack fails on are decoded into the argument map only.
+func RegisterBinding[E any](d *EventDecoder, meta *bind.MetaData, unpack func(log *ethTypes.Log) (E, error)) error {
+	a, err := meta.ParseABI()
+	if err != nil {
+		return err
+	}
+	for _, event := range a.Events {
+		d.add(event, func(log *ethTypes.Log) (any, error) {
+			return unpack(log)
+		})
+	}
+	return nil
+}
+
+func (d *EventDecoder) add(event abi.Event, unpack func(log *ethTypes.Log) (any, error)) {
+	entry := &eventEntry{event: event, unpack: unpack}
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			entry.indexed = append(entry.indexed, arg)
+		}
+	}
+	if event.Anonymous {
+		d.anonymous = append(d.anonymous, entry)
+	} else {
+		d.byTopic[event.ID] = append(d.byTopic[event.ID], entry)
+	}
+}
+
+// DecodedEvent is a log decoded by an EventDecoder.
+type DecodedEvent struct {
+	Event *abi.Event
+	// Args are the arguments by name, indexed arguments of dynamic types hold their hash
+	Args map[string]any
+	// Value is the typed event of a binding (e.g. *contracts_pack.ERC20Transfer), nil if none is registered
+	Value any
+	Log   *ethTypes.Log
+}
+
+// Name returns the event name, e.g. "Transfer".
+func (e *DecodedEvent) Name() string {
+	return e.Event.Name
+}
+
+// Decode decodes a log. Events sharing a signature but not their indexed arguments (e.g. ERC20
+// and ERC721 Transfer) are told apart by the number of topics, events of bindings are preferred
+// over plain ABI events. Anonymous events are matched by their number of indexed arguments and
+// their data when no event is known for topic0.
+func (d *EventDecoder) Decode(log *ethTypes.Log) (*DecodedEvent, error) {
+	if len(log.Topics) > 0 {
+		if event := d.decode(d.byTopic[log.Topics[0]], log, log.Topics[1:]); event != nil {
+			return event, nil
+		}
+	}
+	if event := d.decode(d.anonymous, log, log.Topics); event != nil {
+		return event, nil
+	}
+	if len(log.Topics) == 0 {
+		return nil, fmt.Errorf("%w: log without topics", ErrUnknownEvent)
+	}
+	return nil, fmt.Errorf("%w: topic0 %s", ErrUnknownEvent, log.Topics[0])
+}
+
+// DecodeLog decodes a log returned by ethclient.
+func (d *EventDecoder) DecodeLog(log *ethtype.Log) (*DecodedEvent, error) {
+	return d.Decode(log.ToEthLog())
+}
+
+func (d *EventDecoder) decode(entries []*eventEntry, log *ethTypes.Log, topics []common.Hash) *DecodedEvent {
+	var generic *DecodedEvent
+	for _, entry := range entries {
+		if len(entry.indexed) != len(topics) {
+			continue
+		}
+		args := make(map[string]any)
+		if err := entry.event.Inputs.NonIndexed().UnpackIntoMap(args, log.Data); err != nil {
+			continue
+		}
+		if err := abi.ParseTopicsIntoMap(args, entry.indexed, topics); err != nil {
+			continue
+		}
+		event := &DecodedEvent{Event: &entry.event, Args: args, Log: log}
+		if entry.unpack != nil {
+			if value, err := entry.unpack(log); err == nil {
+				event.Value = value
+				return event
+			}
+		}
+		if generic == nil {
+			generic = event
+		}
+	}
+	return generic
+}
+
+// DecodeEventAs decodes a log into the typed event T of a registered binding.
+func DecodeEventAs[T any](d *EventDecoder, log *ethTypes.Log) (T, error) {
+	var zero T
+	event, err := d.Decode(log)
+	if err != nil {
+		return zero, err
+	}
+	value, ok := event.Value.(T)
+	if !ok {
+		return zero, fmt.Errorf("event %s decoded to %T", event.Event.Name, event.Value)
+	}
+	return value, nil
+}
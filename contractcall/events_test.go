@@ -0,0 +1,81 @@
+package contractcall
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/donutnomad/eths/contracts_pack"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/samber/lo"
+)
+
+const eventsTestABI = `[
+	{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"Ping","anonymous":true,"inputs":[
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"note","type":"string","indexed":false}]}
+]`
+
+func TestEventDecoder(t *testing.T) {
+	erc721 := lo.Must1(abi.JSON(strings.NewReader(eventsTestABI)))
+	d := NewEventDecoder(&erc721)
+	if err := RegisterBinding(d, &contracts_pack.ERC20MetaData, contracts_pack.NewERC20().UnpackEvent); err != nil {
+		t.Fatal(err)
+	}
+	from, to := common.Address{19: 1}, common.Address{19: 2}
+	transfer := contracts_pack.ERC20TransferTopic0()
+
+	// ERC20 Transfer, typed by the binding
+	log := &ethTypes.Log{
+		Topics: []common.Hash{transfer, common.BytesToHash(from[:]), common.BytesToHash(to[:])},
+		Data:   common.BigToHash(big.NewInt(100)).Bytes(),
+	}
+	event, err := d.Decode(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name() != "Transfer" || event.Args["value"].(*big.Int).Int64() != 100 || event.Args["to"] != to {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	typed, err := DecodeEventAs[*contracts_pack.ERC20Transfer](d, log)
+	if err != nil || typed.From != from || typed.Value.Int64() != 100 {
+		t.Fatalf("unexpected typed event %+v %v", typed, err)
+	}
+
+	// ERC721 Transfer has the same topic0 and one more topic
+	log = &ethTypes.Log{Topics: []common.Hash{transfer, common.BytesToHash(from[:]), common.BytesToHash(to[:]), common.BigToHash(big.NewInt(7))}}
+	event, err = d.Decode(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Value != nil || event.Args["tokenId"].(*big.Int).Int64() != 7 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if _, err := DecodeEventAs[*contracts_pack.ERC20Transfer](d, log); err == nil {
+		t.Fatal("expected a type error")
+	}
+
+	// anonymous event without topic0
+	log = &ethTypes.Log{
+		Topics: []common.Hash{common.BytesToHash(from[:])},
+		Data:   lo.Must1(erc721.Events["Ping"].Inputs.NonIndexed().Pack("hello")),
+	}
+	event, err = d.Decode(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name() != "Ping" || event.Args["note"] != "hello" || event.Args["sender"] != from {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	if _, err := d.Decode(&ethTypes.Log{Topics: []common.Hash{{1}, {2}}}); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("expected ErrUnknownEvent, got %v", err)
+	}
+}
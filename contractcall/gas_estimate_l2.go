@@ -0,0 +1,274 @@
+package contractcall
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/donutnomad/eths/contracts_pack"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	// OpGasPriceOracleAddress is the GasPriceOracle predeploy of OP Stack chains.
+	OpGasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
+	// ArbNodeInterfaceAddress is the virtual NodeInterface contract of Arbitrum chains, it only exists for eth_call.
+	ArbNodeInterfaceAddress = common.HexToAddress("0x00000000000000000000000000000000000000C8")
+)
+
+var gasPriceOraclePack = contracts_pack.NewGasPriceOracle()
+var nodeInterfacePack = contracts_pack.NewNodeInterface()
+
+// L2GasEstimate is the cost split of a transaction on an L2.
+type L2GasEstimate struct {
+	// GasLimit is the gas limit to set on the transaction.
+	GasLimit *big.Int
+	// L2Gas is the execution gas.
+	L2Gas *big.Int
+	// L1Gas is the gas for posting the transaction data to L1.
+	// OP: L1 gas, paid through L1Fee on top of GasLimit.
+	// Arb: L2 gas units, already included in GasLimit.
+	L1Gas *big.Int
+	// L1Fee is the L1 data fee in wei.
+	L1Fee *big.Int
+	// L1BaseFee is the L1 base fee used by the estimate.
+	L1BaseFee *big.Int
+}
+
+// IL1DataFee returns the L1 data fee in wei a L2 transaction pays in addition to (OP) or
+// as part of (Arb) its gas limit.
+type IL1DataFee interface {
+	L1DataFee(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*big.Int, error)
+}
+
+type IL2EstimateGas interface {
+	IEstimateGas
+	IL1DataFee
+	EstimateL2Gas(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*L2GasEstimate, error)
+}
+
+var _ IL2EstimateGas = &OpGasEstimateImpl{}
+var _ IL2EstimateGas = &ArbGasEstimateImpl{}
+
+type opCaller interface {
+	ethereum.ContractCaller
+	ethereum.GasEstimator
+}
+
+// OpGasEstimateImpl estimates gas on OP Stack chains (Optimism, Base, ...).
+// EstimateGas returns the L2 gas only, the L1 data fee is charged separately.
+type OpGasEstimateImpl struct {
+	client opCaller
+	logger ILogger
+	Oracle common.Address
+}
+
+func NewOpGasEstimateImpl(client opCaller, logger ILogger) *OpGasEstimateImpl {
+	return &OpGasEstimateImpl{client: client, logger: logger, Oracle: OpGasPriceOracleAddress}
+}
+
+func (i *OpGasEstimateImpl) EstimateGas(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*big.Int, error) {
+	logCallMsg(i.logger, &msg)
+	gas, err := i.client.EstimateGas(ctx, msg)
+	if err != nil {
+		return big.NewInt(0), ParseEvmError(err)
+	}
+	return new(big.Int).SetUint64(gas), nil
+}
+
+// L1DataFee calls GasPriceOracle.getL1Fee with the unsigned transaction built from msg.
+func (i *OpGasEstimateImpl) L1DataFee(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*big.Int, error) {
+	tx, err := unsignedTxBytes(chainId, msg)
+	if err != nil {
+		return nil, err
+	}
+	return callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackGetL1Fee(tx), gasPriceOraclePack.UnpackGetL1Fee)
+}
+
+func (i *OpGasEstimateImpl) EstimateL2Gas(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*L2GasEstimate, error) {
+	l2Gas, err := i.EstimateGas(ctx, chainId, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.Gas = l2Gas.Uint64()
+	tx, err := unsignedTxBytes(chainId, msg)
+	if err != nil {
+		return nil, err
+	}
+	l1Fee, err := callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackGetL1Fee(tx), gasPriceOraclePack.UnpackGetL1Fee)
+	if err != nil {
+		return nil, err
+	}
+	l1Gas, err := callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackGetL1GasUsed(tx), gasPriceOraclePack.UnpackGetL1GasUsed)
+	if err != nil {
+		return nil, err
+	}
+	l1BaseFee, err := callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackL1BaseFee(), gasPriceOraclePack.UnpackL1BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	return &L2GasEstimate{
+		GasLimit:  l2Gas,
+		L2Gas:     copyInt(l2Gas),
+		L1Gas:     l1Gas,
+		L1Fee:     l1Fee,
+		L1BaseFee: l1BaseFee,
+	}, nil
+}
+
+// OpL1FeeParams are the GasPriceOracle parameters of the L1 data fee formula.
+type OpL1FeeParams struct {
+	IsEcotone bool
+	IsFjord   bool
+	L1BaseFee *big.Int
+	// Ecotone and later
+	BlobBaseFee       *big.Int
+	BaseFeeScalar     uint32
+	BlobBaseFeeScalar uint32
+	// Bedrock only, overhead and scalar are deprecated since Ecotone
+	Overhead *big.Int
+	Scalar   *big.Int
+}
+
+// L1FeeParams reads the parameters of the L1 data fee formula from the GasPriceOracle.
+func (i *OpGasEstimateImpl) L1FeeParams(ctx context.Context) (*OpL1FeeParams, error) {
+	var params OpL1FeeParams
+	var err error
+	if params.L1BaseFee, err = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackL1BaseFee(), gasPriceOraclePack.UnpackL1BaseFee); err != nil {
+		return nil, err
+	}
+	// isEcotone/isFjord don't exist before the respective upgrade
+	params.IsEcotone, _ = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackIsEcotone(), gasPriceOraclePack.UnpackIsEcotone)
+	params.IsFjord, _ = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackIsFjord(), gasPriceOraclePack.UnpackIsFjord)
+	if !params.IsEcotone {
+		if params.Overhead, err = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackOverhead(), gasPriceOraclePack.UnpackOverhead); err != nil {
+			return nil, err
+		}
+		if params.Scalar, err = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackScalar(), gasPriceOraclePack.UnpackScalar); err != nil {
+			return nil, err
+		}
+		return &params, nil
+	}
+	if params.BlobBaseFee, err = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackBlobBaseFee(), gasPriceOraclePack.UnpackBlobBaseFee); err != nil {
+		return nil, err
+	}
+	if params.BaseFeeScalar, err = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackBaseFeeScalar(), gasPriceOraclePack.UnpackBaseFeeScalar); err != nil {
+		return nil, err
+	}
+	if params.BlobBaseFeeScalar, err = callOracle(ctx, i.client, i.Oracle, gasPriceOraclePack.PackBlobBaseFeeScalar(), gasPriceOraclePack.UnpackBlobBaseFeeScalar); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// ArbGasEstimateImpl estimates gas on Arbitrum chains with NodeInterface.gasEstimateComponents.
+// EstimateGas returns the full gas limit, which already contains the L1 component.
+type ArbGasEstimateImpl struct {
+	client        ethereum.ContractCaller
+	logger        ILogger
+	NodeInterface common.Address
+}
+
+func NewArbGasEstimateImpl(client ethereum.ContractCaller, logger ILogger) *ArbGasEstimateImpl {
+	return &ArbGasEstimateImpl{client: client, logger: logger, NodeInterface: ArbNodeInterfaceAddress}
+}
+
+func (i *ArbGasEstimateImpl) EstimateGas(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*big.Int, error) {
+	estimate, err := i.EstimateL2Gas(ctx, chainId, msg)
+	if err != nil {
+		return big.NewInt(0), err
+	}
+	return estimate.GasLimit, nil
+}
+
+// L1DataFee returns the L1 component of the gas limit priced at the current L2 base fee.
+func (i *ArbGasEstimateImpl) L1DataFee(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*big.Int, error) {
+	to, creation := arbTarget(msg)
+	out, err := i.call(ctx, msg, nodeInterfacePack.PackGasEstimateL1Component(to, creation, msg.Data))
+	if err != nil {
+		return nil, err
+	}
+	res, err := nodeInterfacePack.UnpackGasEstimateL1Component(out)
+	if err != nil {
+		return nil, errors.Wrap(EthereumRPCErr, err.Error())
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(res.GasEstimateForL1), res.BaseFee), nil
+}
+
+func (i *ArbGasEstimateImpl) EstimateL2Gas(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*L2GasEstimate, error) {
+	logCallMsg(i.logger, &msg)
+	to, creation := arbTarget(msg)
+	out, err := i.call(ctx, msg, nodeInterfacePack.PackGasEstimateComponents(to, creation, msg.Data))
+	if err != nil {
+		return nil, err
+	}
+	res, err := nodeInterfacePack.UnpackGasEstimateComponents(out)
+	if err != nil {
+		return nil, errors.Wrap(EthereumRPCErr, err.Error())
+	}
+	l1Gas := new(big.Int).SetUint64(res.GasEstimateForL1)
+	gasLimit := new(big.Int).SetUint64(res.GasEstimate)
+	return &L2GasEstimate{
+		GasLimit:  gasLimit,
+		L2Gas:     new(big.Int).Sub(gasLimit, l1Gas),
+		L1Gas:     l1Gas,
+		L1Fee:     new(big.Int).Mul(l1Gas, res.BaseFee),
+		L1BaseFee: res.L1BaseFeeEstimate,
+	}, nil
+}
+
+// call runs msg against the NodeInterface, which simulates the call to its arguments.
+func (i *ArbGasEstimateImpl) call(ctx context.Context, msg ethereum.CallMsg, data []byte) ([]byte, error) {
+	nodeInterface := i.NodeInterface
+	msg.To = &nodeInterface
+	msg.Data = data
+	out, err := i.client.CallContract(ctx, msg, nil)
+	if err != nil {
+		return nil, ParseEvmError(err)
+	}
+	return out, nil
+}
+
+func arbTarget(msg ethereum.CallMsg) (common.Address, bool) {
+	if msg.To == nil {
+		return common.Address{}, true
+	}
+	return *msg.To, false
+}
+
+func callOracle[T any](ctx context.Context, client ethereum.ContractCaller, oracle common.Address, data []byte, unpack func([]byte) (T, error)) (T, error) {
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &oracle, Data: data}, nil)
+	if err != nil {
+		var zero T
+		return zero, errors.Wrap(EthereumRPCErr, err.Error())
+	}
+	return unpack(out)
+}
+
+// unsignedTxBytes encodes msg as an unsigned transaction, which is what the GasPriceOracle expects.
+func unsignedTxBytes(chainId *big.Int, msg ethereum.CallMsg) ([]byte, error) {
+	var inner ethTypes.TxData
+	if msg.GasFeeCap != nil || msg.GasTipCap != nil {
+		inner = &ethTypes.DynamicFeeTx{
+			ChainID:    copyInt(chainId),
+			Gas:        msg.Gas,
+			GasTipCap:  copyInt(msg.GasTipCap),
+			GasFeeCap:  copyInt(msg.GasFeeCap),
+			To:         msg.To,
+			Value:      copyInt(msg.Value),
+			Data:       msg.Data,
+			AccessList: msg.AccessList,
+		}
+	} else {
+		inner = &ethTypes.LegacyTx{
+			Gas:      msg.Gas,
+			GasPrice: copyInt(msg.GasPrice),
+			To:       msg.To,
+			Value:    copyInt(msg.Value),
+			Data:     msg.Data,
+		}
+	}
+	return ethTypes.NewTx(inner).MarshalBinary()
+}
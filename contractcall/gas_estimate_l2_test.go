@@ -0,0 +1,140 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeL2Node answers eth_call by contract address and method selector.
+type fakeL2Node struct {
+	gas     uint64
+	returns map[common.Address]map[[4]byte][]byte
+	calls   []ethereum.CallMsg
+}
+
+func (f *fakeL2Node) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.calls = append(f.calls, call)
+	out, ok := f.returns[*call.To][[4]byte(call.Data[:4])]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	return out, nil
+}
+
+func (f *fakeL2Node) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return f.gas, nil
+}
+
+func (f *fakeL2Node) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	return big.NewInt(1_000_000), nil
+}
+
+func (f *fakeL2Node) set(contract common.Address, calldata []byte, args abi.Arguments, values ...any) {
+	out, err := args.Pack(values...)
+	if err != nil {
+		panic(err)
+	}
+	if f.returns[contract] == nil {
+		f.returns[contract] = map[[4]byte][]byte{}
+	}
+	f.returns[contract][[4]byte(calldata[:4])] = out
+}
+
+func uintArgs(types ...string) abi.Arguments {
+	var args abi.Arguments
+	for _, t := range types {
+		typ, _ := abi.NewType(t, "", nil)
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args
+}
+
+func TestOpGasEstimateImpl(t *testing.T) {
+	node := &fakeL2Node{gas: 50_000, returns: map[common.Address]map[[4]byte][]byte{}}
+	oracle := OpGasPriceOracleAddress
+	node.set(oracle, gasPriceOraclePack.PackGetL1Fee(nil), uintArgs("uint256"), big.NewInt(12_345))
+	node.set(oracle, gasPriceOraclePack.PackGetL1GasUsed(nil), uintArgs("uint256"), big.NewInt(1_600))
+	node.set(oracle, gasPriceOraclePack.PackL1BaseFee(), uintArgs("uint256"), big.NewInt(7))
+	node.set(oracle, gasPriceOraclePack.PackIsEcotone(), uintArgs("bool"), true)
+	node.set(oracle, gasPriceOraclePack.PackBlobBaseFee(), uintArgs("uint256"), big.NewInt(1))
+	node.set(oracle, gasPriceOraclePack.PackBaseFeeScalar(), uintArgs("uint32"), uint32(1368))
+	node.set(oracle, gasPriceOraclePack.PackBlobBaseFeeScalar(), uintArgs("uint32"), uint32(810949))
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	estimator := NewOpGasEstimateImpl(node, nil)
+	estimate, err := estimator.EstimateL2Gas(t.Context(), big.NewInt(10), ethereum.CallMsg{To: &to, Data: []byte{1, 2, 3}, GasFeeCap: big.NewInt(100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.GasLimit.Int64() != 50_000 || estimate.L2Gas.Int64() != 50_000 || estimate.L1Gas.Int64() != 1_600 ||
+		estimate.L1Fee.Int64() != 12_345 || estimate.L1BaseFee.Int64() != 7 {
+		t.Fatalf("unexpected estimate %+v", estimate)
+	}
+
+	params, err := estimator.L1FeeParams(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// isFjord reverts on the fake node, like on a chain before Fjord
+	if !params.IsEcotone || params.IsFjord || params.BaseFeeScalar != 1368 || params.BlobBaseFeeScalar != 810949 {
+		t.Fatalf("unexpected params %+v", params)
+	}
+
+	// balance 1_000_000 covers the intrinsic gas (21_048*40) but not the L1 fee on top
+	checker := NewBalanceCheckerImplL2(node, big.NewInt(10), estimator)
+	price := NewGasPrice(big.NewInt(20), big.NewInt(20), big.NewInt(40))
+	if err := NewBalanceCheckerImpl(node).CheckBalance(t.Context(), to, []byte{1, 2, 3}, &to, price); err != nil {
+		t.Fatal(err)
+	}
+	node.set(oracle, gasPriceOraclePack.PackGetL1Fee(nil), uintArgs("uint256"), big.NewInt(200_000))
+	var insufficient *InsufficientBalanceError
+	if err := checker.CheckBalance(t.Context(), to, []byte{1, 2, 3}, &to, price); !errors.As(err, &insufficient) {
+		t.Fatalf("expected InsufficientBalanceError, got %v", err)
+	}
+}
+
+func TestArbGasEstimateImpl(t *testing.T) {
+	node := &fakeL2Node{returns: map[common.Address]map[[4]byte][]byte{}}
+	nodeInterface := ArbNodeInterfaceAddress
+	node.set(nodeInterface, nodeInterfacePack.PackGasEstimateComponents(common.Address{}, false, nil),
+		uintArgs("uint64", "uint64", "uint256", "uint256"), uint64(300_000), uint64(120_000), big.NewInt(10_000_000), big.NewInt(30))
+	node.set(nodeInterface, nodeInterfacePack.PackGasEstimateL1Component(common.Address{}, false, nil),
+		uintArgs("uint64", "uint256", "uint256"), uint64(120_000), big.NewInt(10_000_000), big.NewInt(30))
+
+	from := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	estimator := NewArbGasEstimateImpl(node, nil)
+	msg := ethereum.CallMsg{From: from, Data: []byte{0x60, 0x80}, Value: big.NewInt(1)}
+	estimate, err := estimator.EstimateL2Gas(t.Context(), big.NewInt(42161), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.GasLimit.Int64() != 300_000 || estimate.L2Gas.Int64() != 180_000 || estimate.L1Gas.Int64() != 120_000 ||
+		estimate.L1Fee.Cmp(big.NewInt(1_200_000_000_000)) != 0 || estimate.L1BaseFee.Int64() != 30 {
+		t.Fatalf("unexpected estimate %+v", estimate)
+	}
+	call := node.calls[0]
+	if *call.To != nodeInterface || call.From != from || call.Value.Int64() != 1 {
+		t.Fatalf("unexpected NodeInterface call %+v", call)
+	}
+	args, err := nodeInterfacePack.TryPackGasEstimateComponents(common.Address{}, true, msg.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(call.Data) != string(args) {
+		t.Fatal("contract creation must call gasEstimateComponents(0x0, true, data)")
+	}
+
+	fee, err := estimator.L1DataFee(t.Context(), big.NewInt(42161), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Cmp(estimate.L1Fee) != 0 {
+		t.Fatalf("expected L1 fee %s, got %s", estimate.L1Fee, fee)
+	}
+}
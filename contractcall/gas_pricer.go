@@ -21,7 +21,7 @@ func NewGasPrice(baseFee, maxPriorityFeePerGas, maxFeePerGas *big.Int) *GasPrice
 	return &GasPrice{DynamicGas: &DynamicGas{BaseFee: baseFee, MaxPriorityFeePerGas: maxPriorityFeePerGas, MaxFeePerGas: maxFeePerGas}}
 }
 func GasPriceFromTx(input *ethTypes.Transaction) *GasPrice {
-	if input.Type() == ethTypes.LegacyTxType {
+	if !TxType(input.Type()).IsEIP1559Gas() {
 		return NewGasPriceLegacy(input.GasPrice())
 	} else {
 		_cap := input.GasFeeCap()
@@ -76,3 +76,9 @@ func (p *GasPricerDefault) GetGasPrice(ctx context.Context, chainId *big.Int) (*
 		return NewGasPriceLegacy(gasPrice), nil
 	}
 }
+
+type GasPricerFuncImpl func(ctx context.Context, chainId *big.Int) (*GasPrice, error)
+
+func (i GasPricerFuncImpl) GetGasPrice(ctx context.Context, chainId *big.Int) (*GasPrice, error) {
+	return i(ctx, chainId)
+}
@@ -0,0 +1,233 @@
+package contractcall
+
+import (
+	"context"
+	"maps"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+var ErrEmptyFeeHistory = errors.New("eth_feeHistory returned no blocks")
+
+type IFeeHistory interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+type feeHistoryCaller interface {
+	IFeeHistory
+	ethereum.GasPricer
+}
+
+type GasSpeed int
+
+const (
+	GasSpeedSlow GasSpeed = iota
+	GasSpeedStandard
+	GasSpeedFast
+	GasSpeedUrgent
+)
+
+func (s GasSpeed) String() string {
+	switch s {
+	case GasSpeedSlow:
+		return "slow"
+	case GasSpeedStandard:
+		return "standard"
+	case GasSpeedFast:
+		return "fast"
+	case GasSpeedUrgent:
+		return "urgent"
+	}
+	return "unknown"
+}
+
+// FeeHistoryTier configures one GasSpeed.
+type FeeHistoryTier struct {
+	// Percentile of the priority fees paid in recent blocks, 0-100.
+	Percentile float64
+	// BlocksAhead is how many blocks after the next one MaxFeePerGas must still cover the base fee.
+	BlocksAhead int
+	// FullBlocks predicts the base fee assuming every future block is full (+12.5% per block),
+	// otherwise the average gas used ratio of the history is assumed.
+	FullBlocks bool
+}
+
+// DefaultFeeHistoryTiers are the tiers used by NewFeeHistoryGasPricer, every pricer gets its own copy.
+var DefaultFeeHistoryTiers = map[GasSpeed]FeeHistoryTier{
+	GasSpeedSlow:     {Percentile: 10, BlocksAhead: 2},
+	GasSpeedStandard: {Percentile: 50, BlocksAhead: 3},
+	GasSpeedFast:     {Percentile: 75, BlocksAhead: 4, FullBlocks: true},
+	GasSpeedUrgent:   {Percentile: 95, BlocksAhead: 6, FullBlocks: true},
+}
+
+// FeeHistoryGasPricer is an IGasPricer based on eth_feeHistory.
+//
+// The tip is the average of the per-block reward percentiles of the tier, after dropping
+// empty blocks and rewards more than OutlierFactor times away from the median.
+// MaxFeePerGas is the tip plus the base fee predicted BlocksAhead blocks ahead with the
+// EIP-1559 update rule. Chains without base fee fall back to eth_gasPrice.
+type FeeHistoryGasPricer struct {
+	client feeHistoryCaller
+
+	// Speed is the tier returned by GetGasPrice.
+	Speed GasSpeed
+	Tiers map[GasSpeed]FeeHistoryTier
+	// BlockCount is the number of blocks requested from eth_feeHistory.
+	BlockCount uint64
+	// OutlierFactor, rewards above median*OutlierFactor or below median/OutlierFactor are ignored.
+	OutlierFactor float64
+	// MinTipCap and MaxTipCap are optional bounds for MaxPriorityFeePerGas.
+	MinTipCap *big.Int
+	MaxTipCap *big.Int
+}
+
+func NewFeeHistoryGasPricer(client feeHistoryCaller, speed GasSpeed) *FeeHistoryGasPricer {
+	return &FeeHistoryGasPricer{
+		client:        client,
+		Speed:         speed,
+		Tiers:         maps.Clone(DefaultFeeHistoryTiers),
+		BlockCount:    20,
+		OutlierFactor: 3,
+	}
+}
+
+func (p *FeeHistoryGasPricer) GetGasPrice(ctx context.Context, chainId *big.Int) (*GasPrice, error) {
+	prices, err := p.GetGasPrices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	price, ok := prices[p.Speed]
+	if !ok {
+		return nil, errors.Errorf("gas speed %s is not configured", p.Speed)
+	}
+	return price, nil
+}
+
+// GetGasPrices returns the gas price of every configured tier from a single eth_feeHistory call.
+func (p *FeeHistoryGasPricer) GetGasPrices(ctx context.Context) (map[GasSpeed]*GasPrice, error) {
+	var percentiles []float64
+	for _, tier := range p.Tiers {
+		if !slices.Contains(percentiles, tier.Percentile) {
+			percentiles = append(percentiles, tier.Percentile)
+		}
+	}
+	slices.Sort(percentiles)
+
+	history, err := p.client.FeeHistory(ctx, p.BlockCount, nil, percentiles)
+	if err != nil {
+		return nil, errors.Wrap(EthereumRPCErr, err.Error())
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, ErrEmptyFeeHistory
+	}
+	// the last entry is the base fee of the next block
+	nextBaseFee := history.BaseFee[len(history.BaseFee)-1]
+	if nextBaseFee == nil || nextBaseFee.Sign() == 0 {
+		gasPrice, err := p.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, errors.Wrap(EthereumRPCErr, err.Error())
+		}
+		prices := make(map[GasSpeed]*GasPrice, len(p.Tiers))
+		for speed := range p.Tiers {
+			prices[speed] = NewGasPriceLegacy(gasPrice)
+		}
+		return prices, nil
+	}
+
+	avgRatio := 0.0
+	for _, ratio := range history.GasUsedRatio {
+		avgRatio += ratio
+	}
+	if len(history.GasUsedRatio) > 0 {
+		avgRatio /= float64(len(history.GasUsedRatio))
+	}
+
+	prices := make(map[GasSpeed]*GasPrice, len(p.Tiers))
+	for speed, tier := range p.Tiers {
+		tip := p.tipCap(history, slices.Index(percentiles, tier.Percentile))
+		ratio := avgRatio
+		if tier.FullBlocks {
+			ratio = 1
+		}
+		baseFee := PredictBaseFee(nextBaseFee, ratio, tier.BlocksAhead)
+		// fees can go down, but MaxFeePerGas must cover the next block
+		baseFee = maxInt(baseFee, nextBaseFee)
+		prices[speed] = NewGasPrice(copyInt(nextBaseFee), tip, new(big.Int).Add(baseFee, tip))
+	}
+	return prices, nil
+}
+
+// tipCap returns the filtered average of the rewards at index idx of every block.
+func (p *FeeHistoryGasPricer) tipCap(history *ethereum.FeeHistory, idx int) *big.Int {
+	var rewards []*big.Int
+	for i, reward := range history.Reward {
+		// empty blocks report 0
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		if idx < len(reward) && reward[idx] != nil {
+			rewards = append(rewards, reward[idx])
+		}
+	}
+	tip := averageWithoutOutliers(rewards, p.OutlierFactor)
+	if p.MinTipCap != nil {
+		tip = maxInt(tip, p.MinTipCap)
+	}
+	if p.MaxTipCap != nil {
+		tip = minInt(tip, p.MaxTipCap)
+	}
+	return tip
+}
+
+func averageWithoutOutliers(values []*big.Int, factor float64) *big.Int {
+	if len(values) == 0 {
+		return big.NewInt(0)
+	}
+	sorted := slices.SortedFunc(slices.Values(values), (*big.Int).Cmp)
+	median := sorted[len(sorted)/2]
+
+	var upper, lower *big.Int
+	if factor > 1 && median.Sign() > 0 {
+		f, _ := new(big.Float).Mul(new(big.Float).SetInt(median), big.NewFloat(factor)).Int(nil)
+		upper = f
+		f, _ = new(big.Float).Quo(new(big.Float).SetInt(median), big.NewFloat(factor)).Int(nil)
+		lower = f
+	}
+	sum := new(big.Int)
+	count := 0
+	for _, v := range sorted {
+		if upper != nil && (v.Cmp(upper) > 0 || v.Cmp(lower) < 0) {
+			continue
+		}
+		sum.Add(sum, v)
+		count++
+	}
+	return sum.Div(sum, big.NewInt(int64(count)))
+}
+
+// PredictBaseFee applies the EIP-1559 base fee update rule blocks times, assuming every block
+// uses gasUsedRatio (0-1) of its gas limit and the elasticity multiplier is 2.
+func PredictBaseFee(baseFee *big.Int, gasUsedRatio float64, blocks int) *big.Int {
+	// (gasUsed - gasTarget) / gasTarget in parts per million
+	const scale = 1_000_000
+	delta := int64((gasUsedRatio*2 - 1) * scale)
+	denominator := big.NewInt(scale * params.DefaultBaseFeeChangeDenominator)
+
+	fee := copyInt(baseFee)
+	for range blocks {
+		change := new(big.Int).Mul(fee, big.NewInt(delta))
+		change.Quo(change, denominator)
+		if delta > 0 && change.Sign() == 0 {
+			change.SetInt64(1)
+		}
+		fee.Add(fee, change)
+		if fee.Sign() < 0 {
+			fee.SetInt64(0)
+		}
+	}
+	return fee
+}
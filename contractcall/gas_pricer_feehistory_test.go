@@ -0,0 +1,125 @@
+package contractcall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+type fakeFeeHistory struct {
+	history     *ethereum.FeeHistory
+	percentiles []float64
+}
+
+func (f *fakeFeeHistory) FeeHistory(_ context.Context, _ uint64, _ *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	f.percentiles = rewardPercentiles
+	return f.history, nil
+}
+
+func (f *fakeFeeHistory) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return big.NewInt(777), nil
+}
+
+func TestPredictBaseFee(t *testing.T) {
+	tests := []struct {
+		ratio  float64
+		blocks int
+		want   int64
+	}{
+		{ratio: 1, blocks: 1, want: 1_125_000_000},
+		{ratio: 1, blocks: 2, want: 1_265_625_000},
+		{ratio: 0.5, blocks: 5, want: 1_000_000_000},
+		{ratio: 0, blocks: 1, want: 875_000_000},
+		{ratio: 1, blocks: 0, want: 1_000_000_000},
+	}
+	for _, tt := range tests {
+		got := PredictBaseFee(big.NewInt(1_000_000_000), tt.ratio, tt.blocks)
+		if got.Int64() != tt.want {
+			t.Errorf("PredictBaseFee(ratio=%v, blocks=%d) = %s, want %d", tt.ratio, tt.blocks, got, tt.want)
+		}
+	}
+	// at least +1 wei per block above target
+	if got := PredictBaseFee(big.NewInt(7), 1, 1); got.Int64() != 8 {
+		t.Errorf("expected 8, got %s", got)
+	}
+}
+
+func TestFeeHistoryGasPricer(t *testing.T) {
+	rewards := func(values ...int64) []*big.Int {
+		out := make([]*big.Int, len(values))
+		for i, v := range values {
+			out[i] = big.NewInt(v)
+		}
+		return out
+	}
+	client := &fakeFeeHistory{history: &ethereum.FeeHistory{
+		OldestBlock: big.NewInt(100),
+		// percentiles 10, 50, 75, 95
+		Reward: [][]*big.Int{
+			rewards(1, 2, 3, 4),
+			rewards(1, 2, 3, 400), // outlier at 95
+			rewards(0, 0, 0, 0),   // empty block
+			rewards(1, 2, 3, 5),
+		},
+		BaseFee:      rewards(1000, 1000, 1000, 1000, 1000),
+		GasUsedRatio: []float64{0.5, 0.5, 0, 0.5},
+	}}
+	pricer := NewFeeHistoryGasPricer(client, GasSpeedStandard)
+	prices, err := pricer.GetGasPrices(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.percentiles) != 4 || client.percentiles[0] != 10 || client.percentiles[3] != 95 {
+		t.Fatalf("unexpected percentiles %v", client.percentiles)
+	}
+
+	tests := map[GasSpeed]struct{ tip, maxFee int64 }{
+		// quiet chain, avg ratio 0.375 predicts a falling base fee, the next block's fee is kept
+		GasSpeedSlow:     {tip: 1, maxFee: 1001},
+		GasSpeedStandard: {tip: 2, maxFee: 1002},
+		GasSpeedFast:     {tip: 3, maxFee: 1600 + 3},
+		GasSpeedUrgent:   {tip: 4, maxFee: 2025 + 4},
+	}
+	for speed, want := range tests {
+		got := prices[speed].DynamicGas
+		if got.MaxPriorityFeePerGas.Int64() != want.tip || got.MaxFeePerGas.Int64() != want.maxFee || got.BaseFee.Int64() != 1000 {
+			t.Errorf("%s: got tip=%s maxFee=%s, want tip=%d maxFee=%d", speed, got.MaxPriorityFeePerGas, got.MaxFeePerGas, want.tip, want.maxFee)
+		}
+	}
+
+	pricer.MaxTipCap = big.NewInt(2)
+	price, err := NewFeeHistoryGasPricer(client, GasSpeedUrgent).GetGasPrice(t.Context(), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.DynamicGas.MaxPriorityFeePerGas.Int64() != 4 {
+		t.Fatalf("unexpected urgent tip %s", price.DynamicGas.MaxPriorityFeePerGas)
+	}
+	price, err = pricer.GetGasPrice(t.Context(), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.DynamicGas.MaxPriorityFeePerGas.Int64() != 2 {
+		t.Fatalf("expected capped tip 2, got %s", price.DynamicGas.MaxPriorityFeePerGas)
+	}
+
+	// no base fee, legacy chain
+	client.history.BaseFee = rewards(0, 0)
+	price, err = pricer.GetGasPrice(t.Context(), big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.LegacyGas == nil || price.LegacyGas.GasPrice.Int64() != 777 {
+		t.Fatalf("expected legacy fallback, got %+v", price)
+	}
+}
+
+func TestFeeHistoryGasPricer_OwnTiers(t *testing.T) {
+	pricer := NewFeeHistoryGasPricer(&fakeFeeHistory{}, GasSpeedStandard)
+	pricer.Tiers[GasSpeedStandard] = FeeHistoryTier{Percentile: 60, BlocksAhead: 1}
+	if DefaultFeeHistoryTiers[GasSpeedStandard].Percentile != 50 {
+		t.Fatal("changing the tiers of a pricer must not change DefaultFeeHistoryTiers")
+	}
+}
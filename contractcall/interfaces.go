@@ -59,7 +59,10 @@ type IGasPriceValidator interface {
 // OP:
 // 估算执行 L2 交易所需的L1 数据 gas + L2 gas的数量。
 // estimateL1Gas它是(L1 Gas) 和estimateGas(L2 Gas)的总和。
+// L1 数据费不计入 gas limit，单独收取，见 OpGasEstimateImpl。
 // Arb:
+// NodeInterface.gasEstimateComponents 返回的 gas 已包含 L1 部分，见 ArbGasEstimateImpl。
+// 需要 L1/L2 拆分时使用 IL2EstimateGas。
 type IEstimateGas interface {
 	EstimateGas(ctx context.Context, chainId *big.Int, msg ethereum.CallMsg) (*big.Int, error)
 }
@@ -7,6 +7,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// IPendingNonceAt reads the pending nonce of an account without allocating it.
+type IPendingNonceAt interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
 type INonceAt interface {
 	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
 	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
@@ -0,0 +1,216 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// INonceTracker is implemented by nonce managers that keep local state and
+// need to learn what happened to a nonce after it was handed out.
+// TxBuilder/SendTxBuilder call it automatically when the nonce was obtained via SetNonceBy.
+type INonceTracker interface {
+	IGetNonce
+	// Confirm marks the nonce as successfully broadcast.
+	Confirm(account common.Address, nonce uint64)
+	// Release gives back a nonce whose transaction was never sent or was rejected by the node.
+	// cause is the error that made the send fail (may be nil).
+	Release(ctx context.Context, account common.Address, nonce uint64, cause error)
+}
+
+var _ INonceTracker = &LocalNonceManager{}
+
+// LocalNonceManager hands out nonces from memory so that concurrent senders
+// sharing one account never get the same nonce.
+//
+// The first GetNonce for an account loads the nonce from the chain, later calls
+// just increment it. Released nonces are reused (lowest first) before new ones
+// are allocated. After a "nonce too low" / "nonce too high" error the account is
+// resynchronized with the chain and nonce gaps are queued for reuse.
+type LocalNonceManager struct {
+	client INonceAt
+
+	mu       sync.Mutex
+	accounts map[common.Address]*localNonceState
+}
+
+type localNonceState struct {
+	mu       sync.Mutex
+	synced   bool
+	next     uint64              // lowest nonce never handed out
+	released []uint64            // sorted, handed back and waiting for reuse
+	pending  map[uint64]struct{} // handed out, outcome unknown
+	sent     map[uint64]struct{} // broadcast, not yet known to be mined
+}
+
+func NewLocalNonceManager(client INonceAt) *LocalNonceManager {
+	return &LocalNonceManager{
+		client:   client,
+		accounts: make(map[common.Address]*localNonceState),
+	}
+}
+
+func (n *LocalNonceManager) state(account common.Address) *localNonceState {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	st, ok := n.accounts[account]
+	if !ok {
+		st = &localNonceState{
+			pending: make(map[uint64]struct{}),
+			sent:    make(map[uint64]struct{}),
+		}
+		n.accounts[account] = st
+	}
+	return st
+}
+
+// GetNonce returns the next nonce for account. isPending is only used when the
+// account is loaded from the chain for the first time.
+func (n *LocalNonceManager) GetNonce(ctx context.Context, account common.Address, isPending bool) (uint64, error) {
+	st := n.state(account)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if !st.synced {
+		var chainNonce uint64
+		var err error
+		if isPending {
+			chainNonce, err = n.client.PendingNonceAt(ctx, account)
+		} else {
+			chainNonce, err = n.client.NonceAt(ctx, account, nil)
+		}
+		if err != nil {
+			return 0, err
+		}
+		st.resync(chainNonce)
+		st.synced = true
+	}
+
+	var nonce uint64
+	if len(st.released) > 0 {
+		nonce = st.released[0]
+		st.released = st.released[1:]
+	} else {
+		nonce = st.next
+		st.next++
+	}
+	st.pending[nonce] = struct{}{}
+	return nonce, nil
+}
+
+func (n *LocalNonceManager) Confirm(account common.Address, nonce uint64) {
+	st := n.state(account)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.pending, nonce)
+	st.sent[nonce] = struct{}{}
+}
+
+// Release gives back a nonce. If cause is a "nonce too low", "nonce too high" or "replacement
+// transaction underpriced" error, the nonce may be in use and the account is resynchronized with
+// the chain instead.
+func (n *LocalNonceManager) Release(ctx context.Context, account common.Address, nonce uint64, cause error) {
+	st := n.state(account)
+	st.mu.Lock()
+	delete(st.pending, nonce)
+	if isNonceError(cause) || isReplacementUnderpriced(cause) {
+		st.synced = false
+		st.mu.Unlock()
+		// best effort, the next GetNonce retries when this fails
+		_, _ = n.Resync(ctx, account)
+		return
+	}
+	defer st.mu.Unlock()
+	st.release(nonce)
+}
+
+// Resync reloads the pending nonce of account from the chain and returns the
+// nonce gaps it found. A gap is a nonce below the local counter that is neither
+// in flight nor known to the node, e.g. because its transaction was dropped from
+// the mempool. Gaps are handed out again by the following GetNonce calls.
+func (n *LocalNonceManager) Resync(ctx context.Context, account common.Address) ([]uint64, error) {
+	chainNonce, err := n.client.PendingNonceAt(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	st := n.state(account)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.resync(chainNonce)
+	st.synced = true
+	return slices.Clone(st.released), nil
+}
+
+// Gaps returns the nonces currently waiting to be reused for account.
+func (n *LocalNonceManager) Gaps(account common.Address) []uint64 {
+	st := n.state(account)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return slices.Clone(st.released)
+}
+
+// Reset forgets everything about account, the next GetNonce loads it from the chain.
+func (n *LocalNonceManager) Reset(account common.Address) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.accounts, account)
+}
+
+func (s *localNonceState) release(nonce uint64) {
+	if nonce >= s.next {
+		return
+	}
+	if nonce == s.next-1 {
+		s.next--
+		// shrink the counter over trailing released nonces
+		for len(s.released) > 0 && s.released[len(s.released)-1] == s.next-1 {
+			s.released = s.released[:len(s.released)-1]
+			s.next--
+		}
+		return
+	}
+	idx, found := slices.BinarySearch(s.released, nonce)
+	if !found {
+		s.released = slices.Insert(s.released, idx, nonce)
+	}
+}
+
+// resync aligns the local state with chainNonce, the pending nonce reported by the node.
+func (s *localNonceState) resync(chainNonce uint64) {
+	for nonce := range s.sent {
+		if nonce < chainNonce {
+			delete(s.sent, nonce)
+		}
+	}
+	// The node would report a higher pending nonce if it knew a transaction
+	// with this nonce, so it was dropped.
+	delete(s.sent, chainNonce)
+
+	next := chainNonce
+	for nonce := range s.pending {
+		next = max(next, nonce+1)
+	}
+	for nonce := range s.sent {
+		next = max(next, nonce+1)
+	}
+	s.released = s.released[:0]
+	for nonce := chainNonce; nonce < next; nonce++ {
+		_, isPending := s.pending[nonce]
+		_, isSent := s.sent[nonce]
+		if !isPending && !isSent {
+			s.released = append(s.released, nonce)
+		}
+	}
+	s.next = next
+}
+
+func isNonceError(err error) bool {
+	var evmErr *EvmError
+	if !errors.As(err, &evmErr) {
+		return false
+	}
+	return evmErr.IsNonceTooLow() || evmErr.IsNonceTooHigh()
+}
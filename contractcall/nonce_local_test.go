@@ -0,0 +1,186 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fakeNonceClient struct {
+	mu      sync.Mutex
+	pending uint64
+	calls   int
+}
+
+func (f *fakeNonceClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return f.PendingNonceAt(ctx, account)
+}
+
+func (f *fakeNonceClient) PendingNonceAt(_ context.Context, _ common.Address) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.pending, nil
+}
+
+func (f *fakeNonceClient) set(nonce uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = nonce
+}
+
+var nonceTestAccount = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+func TestLocalNonceManager_Concurrent(t *testing.T) {
+	client := &fakeNonceClient{pending: 7}
+	m := NewLocalNonceManager(client)
+
+	const workers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var got []uint64
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := m.GetNonce(t.Context(), nonceTestAccount, true)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			got = append(got, nonce)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	slices.Sort(got)
+	for i, nonce := range got {
+		if nonce != uint64(7+i) {
+			t.Fatalf("expected nonce %d, got %d", 7+i, nonce)
+		}
+	}
+	if client.calls != 1 {
+		t.Fatalf("expected 1 chain query, got %d", client.calls)
+	}
+}
+
+func TestLocalNonceManager_ReleaseReuse(t *testing.T) {
+	m := NewLocalNonceManager(&fakeNonceClient{pending: 0})
+	ctx := t.Context()
+
+	for i := range 4 {
+		nonce, _ := m.GetNonce(ctx, nonceTestAccount, true)
+		if nonce != uint64(i) {
+			t.Fatalf("expected %d, got %d", i, nonce)
+		}
+	}
+	m.Confirm(nonceTestAccount, 0)
+	m.Release(ctx, nonceTestAccount, 1, errors.New("boom"))
+	if gaps := m.Gaps(nonceTestAccount); !slices.Equal(gaps, []uint64{1}) {
+		t.Fatalf("expected gaps [1], got %v", gaps)
+	}
+	// releasing the highest nonce shrinks the counter instead of leaving a gap
+	m.Release(ctx, nonceTestAccount, 3, nil)
+
+	nonce, _ := m.GetNonce(ctx, nonceTestAccount, true)
+	if nonce != 1 {
+		t.Fatalf("expected released nonce 1, got %d", nonce)
+	}
+	nonce, _ = m.GetNonce(ctx, nonceTestAccount, true)
+	if nonce != 3 {
+		t.Fatalf("expected nonce 3, got %d", nonce)
+	}
+}
+
+func TestLocalNonceManager_ResyncOnNonceTooLow(t *testing.T) {
+	client := &fakeNonceClient{pending: 5}
+	m := NewLocalNonceManager(client)
+	ctx := t.Context()
+
+	nonce, _ := m.GetNonce(ctx, nonceTestAccount, true)
+	if nonce != 5 {
+		t.Fatalf("expected 5, got %d", nonce)
+	}
+	// another wallet instance used nonces 5..9
+	client.set(10)
+	cause := &SendTransactionError{Err: &EvmError{Message: "nonce too low: next nonce 10, tx nonce 5"}}
+	m.Release(ctx, nonceTestAccount, nonce, cause)
+
+	nonce, _ = m.GetNonce(ctx, nonceTestAccount, true)
+	if nonce != 10 {
+		t.Fatalf("expected 10 after resync, got %d", nonce)
+	}
+}
+
+func TestLocalNonceManager_DroppedTxGap(t *testing.T) {
+	client := &fakeNonceClient{pending: 0}
+	m := NewLocalNonceManager(client)
+	ctx := t.Context()
+
+	for range 3 {
+		nonce, _ := m.GetNonce(ctx, nonceTestAccount, true)
+		m.Confirm(nonceTestAccount, nonce)
+	}
+	// nonce 0 was mined, nonce 1 was dropped from the mempool, so the node reports 1
+	client.set(1)
+	gaps, err := m.Resync(ctx, nonceTestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(gaps, []uint64{1}) {
+		t.Fatalf("expected gaps [1], got %v", gaps)
+	}
+	nonce, _ := m.GetNonce(ctx, nonceTestAccount, true)
+	if nonce != 1 {
+		t.Fatalf("expected gap nonce 1, got %d", nonce)
+	}
+	nonce, _ = m.GetNonce(ctx, nonceTestAccount, true)
+	if nonce != 3 {
+		t.Fatalf("expected 3, got %d", nonce)
+	}
+}
+
+func TestTxBuilder_SettleNonce(t *testing.T) {
+	rpcErr := func(msg string) error {
+		return &SendTransactionError{Err: &EvmError{Message: msg, ErrCode: -32000}}
+	}
+	tests := []struct {
+		name  string
+		err   error
+		sent  bool
+		reuse bool
+	}{
+		{"rejected", rpcErr("transaction underpriced"), false, true},
+		{"already known", rpcErr("already known"), true, false},
+		{"replacement underpriced", rpcErr("replacement transaction underpriced"), false, false},
+		{"timeout", &SendTransactionError{Err: ParseEvmError(context.DeadlineExceeded)}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeNonceClient{pending: 5}
+			m := NewLocalNonceManager(client)
+			b := NewTxBuilder(t.Context(), big.NewInt(1)).SetFrom(nonceTestAccount).SetNonceBy(m)
+			if b.err != nil {
+				t.Fatal(b.err)
+			}
+			if tt.name == "replacement underpriced" {
+				// the node holds another transaction with nonce 5
+				client.set(6)
+			}
+			if sent := b.settleNonce(tt.err); sent != tt.sent {
+				t.Fatalf("expected sent=%v, got %v", tt.sent, sent)
+			}
+			nonce, _ := m.GetNonce(t.Context(), nonceTestAccount, true)
+			if reused := nonce == 5; reused != tt.reuse {
+				t.Fatalf("expected reuse=%v, got nonce %d", tt.reuse, nonce)
+			}
+		})
+	}
+}
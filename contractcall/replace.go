@@ -0,0 +1,250 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// DefaultPriceBumpPercent is the minimum fee increase geth/reth require to replace a pending transaction.
+	DefaultPriceBumpPercent = 10
+	// DefaultBlobPriceBumpPercent is the minimum fee increase required to replace a pending blob transaction.
+	DefaultBlobPriceBumpPercent = 100
+)
+
+var ErrCancelBlobTx = errors.New("blob transactions can only be replaced by blob transactions, use SpeedUp instead")
+var ErrReplaceFeeCapExceeded = errors.New("replacement fee exceeds the fee cap")
+var ErrReplaceSenderMismatch = errors.New("payer is not the sender of the transaction to replace")
+
+type ReplaceTxOption struct {
+	// BumpPercent is the fee increase over the original transaction.
+	// Defaults to DefaultPriceBumpPercent (DefaultBlobPriceBumpPercent for blob transactions),
+	// smaller values are raised to the default because nodes reject them.
+	BumpPercent int64
+	// GasPricer is optional, when set the higher of the bumped price and the current market price is used.
+	GasPricer IGasPricer
+	// FeeCap is optional, a hard cap for MaxFeePerGas (GasPrice for legacy transactions).
+	// Fees above it are clamped, ErrReplaceFeeCapExceeded is returned when even the
+	// minimum replacement fee is above it.
+	FeeCap *big.Int
+	// NoSend signs the replacement without broadcasting it.
+	NoSend bool
+}
+
+// SpeedUp resends tx with the same nonce and raised fees.
+// The replacement keeps the recipient, value, data and gas limit of tx. tx must be signed by payer.
+func SpeedUp(ctx context.Context, client ethereum.TransactionSender, tx ITx, payer ISigner, opt ...ReplaceTxOption) (*ethTypes.Transaction, error) {
+	replacement := NewTx(tx.ToTransaction(), tx.ChainID())
+	replacement.SetSidecar(tx.Sidecar())
+	return replace(ctx, client, tx, replacement, payer, opt...)
+}
+
+// SpeedUpTransaction is SpeedUp for a go-ethereum transaction.
+func SpeedUpTransaction(ctx context.Context, client ethereum.TransactionSender, tx *ethTypes.Transaction, payer ISigner, opt ...ReplaceTxOption) (*ethTypes.Transaction, error) {
+	return SpeedUp(ctx, client, NewTx(tx, tx.ChainId()), payer, opt...)
+}
+
+// Cancel replaces tx with a zero-value transfer from payer to itself at the same nonce and with raised fees.
+// Set-code transactions are cancelled with a dynamic fee transaction, blob transactions cannot be cancelled.
+// tx must be signed by payer.
+func Cancel(ctx context.Context, client ethereum.TransactionSender, tx ITx, payer ISigner, opt ...ReplaceTxOption) (*ethTypes.Transaction, error) {
+	txType := tx.TxType()
+	switch txType {
+	case BlobTxType:
+		return nil, ErrCancelBlobTx
+	case SetCodeTxType:
+		txType = DynamicFeeTxType
+	}
+	self := payer.Address()
+	replacement := NewTxWith(txType, tx.ChainID())
+	replacement.SetNonce(tx.Nonce())
+	replacement.SetTo(&self)
+	replacement.SetValue(big.NewInt(0))
+	replacement.SetGas(params.TxGas)
+	return replace(ctx, client, tx, replacement, payer, opt...)
+}
+
+// CancelTransaction is Cancel for a go-ethereum transaction.
+func CancelTransaction(ctx context.Context, client ethereum.TransactionSender, tx *ethTypes.Transaction, payer ISigner, opt ...ReplaceTxOption) (*ethTypes.Transaction, error) {
+	return Cancel(ctx, client, NewTx(tx, tx.ChainId()), payer, opt...)
+}
+
+// BumpGasPrice returns gasPrice with every fee raised by percent, rounded up and by at least 1 wei.
+func BumpGasPrice(gasPrice *GasPrice, percent int64) *GasPrice {
+	if gasPrice.LegacyGas != nil {
+		return NewGasPriceLegacy(bumpFee(gasPrice.LegacyGas.GasPrice, percent))
+	}
+	dynamic := gasPrice.DynamicGas
+	return NewGasPrice(copyInt(dynamic.BaseFee), bumpFee(dynamic.MaxPriorityFeePerGas, percent), bumpFee(dynamic.MaxFeePerGas, percent))
+}
+
+// MaxGasPrice returns the field-wise maximum of a and b. Both must be of the same kind (legacy or dynamic).
+func MaxGasPrice(a, b *GasPrice) *GasPrice {
+	if a.LegacyGas != nil && b.LegacyGas != nil {
+		return NewGasPriceLegacy(maxInt(a.LegacyGas.GasPrice, b.LegacyGas.GasPrice))
+	}
+	if a.DynamicGas != nil && b.DynamicGas != nil {
+		return NewGasPrice(
+			maxInt(a.DynamicGas.BaseFee, b.DynamicGas.BaseFee),
+			maxInt(a.DynamicGas.MaxPriorityFeePerGas, b.DynamicGas.MaxPriorityFeePerGas),
+			maxInt(a.DynamicGas.MaxFeePerGas, b.DynamicGas.MaxFeePerGas),
+		)
+	}
+	return a
+}
+
+// replacementGasPrice computes the fees for a replacement of original.
+func replacementGasPrice(ctx context.Context, original ITx, o ReplaceTxOption) (*GasPrice, error) {
+	minBump := int64(DefaultPriceBumpPercent)
+	if original.TxType() == BlobTxType {
+		minBump = DefaultBlobPriceBumpPercent
+	}
+	bump := max(o.BumpPercent, minBump)
+
+	var price *GasPrice
+	if original.TxType().IsEIP1559Gas() {
+		price = NewGasPrice(big.NewInt(0), original.MaxPriorityFeePerGas(), original.MaxFeePerGas())
+	} else {
+		price = NewGasPriceLegacy(original.GasPrice())
+	}
+	minimum := BumpGasPrice(price, minBump)
+	price = BumpGasPrice(price, bump)
+	if o.GasPricer != nil {
+		market, err := o.GasPricer.GetGasPrice(ctx, original.ChainID())
+		if err != nil {
+			return nil, err
+		}
+		market = toGasPriceKind(market, price.LegacyGas != nil)
+		price = MaxGasPrice(price, market)
+	}
+	if o.FeeCap != nil {
+		if feeCapOf(minimum).Cmp(o.FeeCap) > 0 {
+			return nil, ErrReplaceFeeCapExceeded
+		}
+		price = capGasPrice(price, o.FeeCap)
+	}
+	return price, nil
+}
+
+func feeCapOf(price *GasPrice) *big.Int {
+	if price.LegacyGas != nil {
+		return price.LegacyGas.GasPrice
+	}
+	return price.DynamicGas.MaxFeePerGas
+}
+
+// capGasPrice clamps the fee cap (and the tip, which can't exceed it) of price to feeCap.
+func capGasPrice(price *GasPrice, feeCap *big.Int) *GasPrice {
+	if price.LegacyGas != nil {
+		return NewGasPriceLegacy(minInt(price.LegacyGas.GasPrice, feeCap))
+	}
+	maxFee := minInt(price.DynamicGas.MaxFeePerGas, feeCap)
+	return NewGasPrice(copyInt(price.DynamicGas.BaseFee), minInt(price.DynamicGas.MaxPriorityFeePerGas, maxFee), maxFee)
+}
+
+func replace(ctx context.Context, client ethereum.TransactionSender, original, replacement ITx, payer ISigner, opt ...ReplaceTxOption) (*ethTypes.Transaction, error) {
+	var o ReplaceTxOption
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	if err := checkSender(original, payer); err != nil {
+		return nil, err
+	}
+	price, err := replacementGasPrice(ctx, original, o)
+	if err != nil {
+		return nil, err
+	}
+	applyGasPrice(replacement, price)
+	if original.TxType() == BlobTxType {
+		replacement.SetMaxFeePerBlobGas(bumpFee(original.MaxFeePerBlobGas(), max(o.BumpPercent, DefaultBlobPriceBumpPercent)))
+	}
+	return signAndSend(ctx, client, replacement, payer, o.NoSend)
+}
+
+// checkSender makes sure payer signed original, otherwise the replacement would use another account's nonce.
+func checkSender(original ITx, payer ISigner) error {
+	from, err := ethTypes.Sender(ethTypes.LatestSignerForChainID(original.ChainID()), original.ToTransaction())
+	if err != nil {
+		return fmt.Errorf("failed to recover the sender of the transaction to replace: %w", err)
+	}
+	if from != payer.Address() {
+		return fmt.Errorf("%w: sender %s, payer %s", ErrReplaceSenderMismatch, from, payer.Address())
+	}
+	return nil
+}
+
+func signAndSend(ctx context.Context, client ethereum.TransactionSender, txWrapper ITx, payer ISigner, noSend bool) (*ethTypes.Transaction, error) {
+	if err := txWrapper.Sign(payer); err != nil {
+		return nil, err
+	}
+	tx := txWrapper.ToTransaction()
+	if noSend {
+		return tx, nil
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		errSend := &SendTransactionError{
+			Tx:  tx,
+			Err: ParseEvmError(err),
+		}
+		return nil, fmt.Errorf("ethereum send transaction failed: %w,%w", errSend, EthereumRPCErr)
+	}
+	return tx, nil
+}
+
+// applyGasPrice writes price into tx, legacy prices are used as both fee caps of dynamic fee transactions.
+func applyGasPrice(tx ITx, price *GasPrice) {
+	price = toGasPriceKind(price, !tx.TxType().IsEIP1559Gas())
+	if price.LegacyGas != nil {
+		tx.SetGasPrice(price.LegacyGas.GasPrice)
+		return
+	}
+	tx.SetMaxPriorityFeePerGas(price.DynamicGas.MaxPriorityFeePerGas)
+	tx.SetMaxFeePerGas(price.DynamicGas.MaxFeePerGas)
+}
+
+// toGasPriceKind converts price to a legacy or dynamic GasPrice.
+func toGasPriceKind(price *GasPrice, legacy bool) *GasPrice {
+	switch {
+	case legacy && price.LegacyGas == nil:
+		return NewGasPriceLegacy(copyInt(price.DynamicGas.MaxFeePerGas))
+	case !legacy && price.DynamicGas == nil:
+		p := copyInt(price.LegacyGas.GasPrice)
+		return NewGasPrice(big.NewInt(0), p, p)
+	}
+	return price
+}
+
+func bumpFee(fee *big.Int, percent int64) *big.Int {
+	fee = copyInt(fee)
+	// ceil(fee * (100 + percent) / 100)
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return copyInt(a)
+	}
+	return copyInt(b)
+}
+
+func maxInt(a, b *big.Int) *big.Int {
+	if a == nil {
+		return copyInt(b)
+	}
+	if b == nil || a.Cmp(b) >= 0 {
+		return copyInt(a)
+	}
+	return copyInt(b)
+}
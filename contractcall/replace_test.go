@@ -0,0 +1,166 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/donutnomad/blockchain-alg/xecdsa"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+type recordingSender struct {
+	sent []*ethTypes.Transaction
+}
+
+func (r *recordingSender) SendTransaction(_ context.Context, tx *ethTypes.Transaction) error {
+	r.sent = append(r.sent, tx)
+	return nil
+}
+
+func newTestSigner(t *testing.T) *EcdsaPrivateKeySigner {
+	t.Helper()
+	key, err := xecdsa.GenerateKey(xecdsa.Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEcdsaPrivateKeySigner(key)
+}
+
+func TestBumpFee(t *testing.T) {
+	tests := []struct {
+		fee     int64
+		percent int64
+		want    int64
+	}{
+		{fee: 100, percent: 10, want: 110},
+		{fee: 101, percent: 10, want: 112}, // rounded up
+		{fee: 0, percent: 10, want: 1},     // at least 1 wei
+		{fee: 5, percent: 100, want: 10},
+	}
+	for _, tt := range tests {
+		got := bumpFee(big.NewInt(tt.fee), tt.percent)
+		if got.Int64() != tt.want {
+			t.Errorf("bumpFee(%d, %d) = %d, want %d", tt.fee, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestSpeedUp_Dynamic(t *testing.T) {
+	signer := newTestSigner(t)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	original := NewTxWith(DynamicFeeTxType, 1)
+	original.SetNonce(9)
+	original.SetTo(&to)
+	original.SetValue(big.NewInt(5))
+	original.SetData([]byte{1, 2, 3})
+	original.SetGas(50000)
+	original.SetMaxPriorityFeePerGas(big.NewInt(1_000))
+	original.SetMaxFeePerGas(big.NewInt(10_000))
+	if err := original.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &recordingSender{}
+	tx, err := SpeedUp(t.Context(), sender, original, signer, ReplaceTxOption{BumpPercent: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Hash() != tx.Hash() {
+		t.Fatal("replacement was not broadcast")
+	}
+	if tx.Nonce() != 9 || tx.Gas() != 50000 || *tx.To() != to || tx.Value().Int64() != 5 {
+		t.Fatalf("replacement changed the transaction: %+v", tx)
+	}
+	// 5% is below the node minimum, 10% is used
+	if tx.GasTipCap().Int64() != 1_100 || tx.GasFeeCap().Int64() != 11_000 {
+		t.Fatalf("unexpected fees tip=%s cap=%s", tx.GasTipCap(), tx.GasFeeCap())
+	}
+	from, err := ethTypes.Sender(ethTypes.LatestSignerForChainID(big.NewInt(1)), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != signer.Address() {
+		t.Fatalf("expected sender %s, got %s", signer.Address(), from)
+	}
+}
+
+func TestSpeedUp_MarketPriceWins(t *testing.T) {
+	signer := newTestSigner(t)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	original := NewTxWith(LegacyTxType, 1)
+	original.SetTo(&to)
+	original.SetGas(21000)
+	original.SetGasPrice(big.NewInt(100))
+	if err := original.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+
+	pricer := GasPricerFuncImpl(func(ctx context.Context, chainId *big.Int) (*GasPrice, error) {
+		return NewGasPrice(big.NewInt(400), big.NewInt(100), big.NewInt(500)), nil
+	})
+	tx, err := SpeedUp(t.Context(), &recordingSender{}, original, signer, ReplaceTxOption{GasPricer: pricer, NoSend: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != ethTypes.LegacyTxType || tx.GasPrice().Int64() != 500 {
+		t.Fatalf("expected legacy gas price 500, got type %d price %s", tx.Type(), tx.GasPrice())
+	}
+}
+
+func TestCancel(t *testing.T) {
+	signer := newTestSigner(t)
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	original := NewTxWith(DynamicFeeTxType, 1)
+	original.SetNonce(3)
+	original.SetTo(&to)
+	original.SetValue(big.NewInt(1e18))
+	original.SetData([]byte{0xde, 0xad})
+	original.SetGas(100000)
+	original.SetMaxPriorityFeePerGas(big.NewInt(2))
+	original.SetMaxFeePerGas(big.NewInt(20))
+	if err := original.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := CancelTransaction(t.Context(), &recordingSender{}, original.ToTransaction(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 3 || *tx.To() != signer.Address() || tx.Value().Sign() != 0 || len(tx.Data()) != 0 || tx.Gas() != 21000 {
+		t.Fatalf("unexpected cancel transaction: %+v", tx)
+	}
+	if tx.GasTipCap().Int64() != 3 || tx.GasFeeCap().Int64() != 22 {
+		t.Fatalf("unexpected fees tip=%s cap=%s", tx.GasTipCap(), tx.GasFeeCap())
+	}
+
+	blob := NewTxWith(BlobTxType, 1)
+	if _, err := Cancel(t.Context(), &recordingSender{}, blob, signer); err != ErrCancelBlobTx {
+		t.Fatalf("expected ErrCancelBlobTx, got %v", err)
+	}
+}
+
+func TestReplace_SenderMismatch(t *testing.T) {
+	signer := newTestSigner(t)
+	original := NewTxWith(DynamicFeeTxType, 1)
+	original.SetNonce(3)
+	original.SetGas(21000)
+	original.SetMaxPriorityFeePerGas(big.NewInt(2))
+	original.SetMaxFeePerGas(big.NewInt(20))
+	if err := original.Sign(newTestSigner(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &recordingSender{}
+	if _, err := SpeedUp(t.Context(), sender, original, signer); !errors.Is(err, ErrReplaceSenderMismatch) {
+		t.Fatalf("expected ErrReplaceSenderMismatch, got %v", err)
+	}
+	if _, err := Cancel(t.Context(), sender, original, signer); !errors.Is(err, ErrReplaceSenderMismatch) {
+		t.Fatalf("expected ErrReplaceSenderMismatch, got %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected nothing sent, got %d transactions", len(sender.sent))
+	}
+}
@@ -1,9 +1,12 @@
 package contractcall
 
 import (
+	"math/big"
+
 	"github.com/donutnomad/blockchain-alg/xecdsa"
 	"github.com/donutnomad/blockchain-alg/xsecp256k1"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 type ISigner interface {
@@ -11,6 +14,14 @@ type ISigner interface {
 	Sign(msg []byte) (*xecdsa.RSVSignature, error)
 }
 
+// ITxSigner is implemented by signers that sign whole transactions instead of raw hashes,
+// e.g. external signers which show the transaction to an operator before signing.
+// ITx.Sign prefers SignTx over Sign when the signer implements it.
+type ITxSigner interface {
+	ISigner
+	SignTx(tx *ethTypes.Transaction, chainId *big.Int) (*ethTypes.Transaction, error)
+}
+
 type NoOpSigner struct {
 	address common.Address
 	signFn  func(data []byte)
@@ -0,0 +1,126 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/donutnomad/blockchain-alg/xecdsa"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var ErrSignHashUnsupported = errors.New("signer doesn't sign raw hashes")
+
+// DefaultClefTimeout leaves room for an operator to confirm the request in clef.
+const DefaultClefTimeout = 2 * time.Minute
+
+var _ ITxSigner = (*ClefSigner)(nil)
+
+// ClefSigner signs transactions with a clef compatible external signer (account_signTransaction).
+// Clef never signs raw hashes, so Sign returns ErrSignHashUnsupported and authorizations
+// of EIP-7702 can't be signed with it.
+type ClefSigner struct {
+	client  *rpc.Client
+	address common.Address
+	Timeout time.Duration
+}
+
+func NewClefSigner(client *rpc.Client, address common.Address) *ClefSigner {
+	return &ClefSigner{client: client, address: address, Timeout: DefaultClefTimeout}
+}
+
+// DialClefSigner connects to the clef endpoint (ipc path or http url).
+func DialClefSigner(ctx context.Context, endpoint string, address common.Address) (*ClefSigner, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewClefSigner(client, address), nil
+}
+
+func (s *ClefSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *ClefSigner) Sign([]byte) (*xecdsa.RSVSignature, error) {
+	return nil, ErrSignHashUnsupported
+}
+
+// Accounts returns the accounts managed by clef.
+func (s *ClefSigner) Accounts(ctx context.Context) ([]common.Address, error) {
+	var accounts []common.Address
+	if err := s.client.CallContext(ctx, &accounts, "account_list"); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (s *ClefSigner) SignTx(tx *ethTypes.Transaction, chainId *big.Int) (*ethTypes.Transaction, error) {
+	args, err := clefTxArgs(s.address, tx, chainId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+
+	var res struct {
+		Raw hexutil.Bytes         `json:"raw"`
+		Tx  *ethTypes.Transaction `json:"tx"`
+	}
+	if err := s.client.CallContext(ctx, &res, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	if res.Tx == nil {
+		return nil, errors.New("clef returned no transaction")
+	}
+	return res.Tx, nil
+}
+
+func clefTxArgs(from common.Address, tx *ethTypes.Transaction, chainId *big.Int) (*apitypes.SendTxArgs, error) {
+	input := hexutil.Bytes(tx.Data())
+	args := &apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(from),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Input:   &input,
+		ChainID: (*hexutil.Big)(chainId),
+	}
+	if to := tx.To(); to != nil {
+		mixed := common.NewMixedcaseAddress(*to)
+		args.To = &mixed
+	}
+	switch tx.Type() {
+	case ethTypes.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case ethTypes.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	case ethTypes.DynamicFeeTxType, ethTypes.BlobTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	default:
+		return nil, fmt.Errorf("clef doesn't support transaction type %d", tx.Type())
+	}
+	if tx.Type() == ethTypes.BlobTxType {
+		sidecar := tx.BlobTxSidecar()
+		if sidecar == nil {
+			return nil, errors.New("clef requires the blob sidecar to sign a blob transaction")
+		}
+		args.BlobFeeCap = (*hexutil.Big)(tx.BlobGasFeeCap())
+		args.BlobHashes = tx.BlobHashes()
+		args.Blobs = sidecar.Blobs
+		args.Commitments = sidecar.Commitments
+		args.Proofs = sidecar.Proofs
+	}
+	return args, nil
+}
@@ -0,0 +1,134 @@
+package contractcall
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/donutnomad/blockchain-alg/xecdsa"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var ErrSignerAddressMismatch = errors.New("signature doesn't recover to the signer address")
+
+// HTTPSignRequest is the body posted to the signing service.
+type HTTPSignRequest struct {
+	Address common.Address `json:"address"`
+	Hash    hexutil.Bytes  `json:"hash"`
+}
+
+// HTTPSignResponse is the body returned by the signing service, Signature is r || s || v
+// where v is 0/1 or 27/28.
+type HTTPSignResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// HTTPSigner delegates signing to a remote service that holds the key, e.g. a KMS/HSM gateway.
+//
+// Every Sign posts an HTTPSignRequest as JSON to Endpoint and expects an HTTPSignResponse.
+// Non 2xx responses are returned as errors. The signature is verified against the address
+// before it is used.
+type HTTPSigner struct {
+	Endpoint string
+	address  common.Address
+	Client   *http.Client
+	// Header is added to every request, e.g. Authorization
+	Header  http.Header
+	Timeout time.Duration
+}
+
+func NewHTTPSigner(endpoint string, address common.Address) *HTTPSigner {
+	return &HTTPSigner{
+		Endpoint: endpoint,
+		address:  address,
+		Client:   http.DefaultClient,
+		Header:   http.Header{},
+		Timeout:  30 * time.Second,
+	}
+}
+
+func (s *HTTPSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *HTTPSigner) Sign(msg []byte) (*xecdsa.RSVSignature, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	return s.SignContext(ctx, msg)
+}
+
+func (s *HTTPSigner) SignContext(ctx context.Context, msg []byte) (*xecdsa.RSVSignature, error) {
+	body, err := json.Marshal(HTTPSignRequest{Address: s.address, Hash: msg})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "http signer")
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrap(err, "http signer")
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("http signer: %s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+	var res HTTPSignResponse
+	if err := json.Unmarshal(respBody, &res); err != nil {
+		return nil, errors.Wrap(err, "http signer: decode response")
+	}
+	return parseRSVSignature(s.address, msg, res.Signature)
+}
+
+// parseRSVSignature parses a 65 bytes r || s || v signature of hash and checks it was made by address.
+// A high s, which transactions may not carry (EIP-2), is replaced by N-s with the other v.
+func parseRSVSignature(address common.Address, hash []byte, sig []byte) (*xecdsa.RSVSignature, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("invalid signature v %d", sig[64])
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	sValue := new(big.Int).SetBytes(sig[32:64])
+	n := crypto.S256().Params().N
+	if sValue.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		sValue.Sub(n, sValue)
+		v ^= 1
+	}
+	normalized := make([]byte, crypto.SignatureLength)
+	r.FillBytes(normalized[:32])
+	sValue.FillBytes(normalized[32:64])
+	normalized[64] = v
+	pub, err := crypto.SigToPub(hash, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyToAddress(*pub) != address {
+		return nil, ErrSignerAddressMismatch
+	}
+	v += 27
+	return xecdsa.NewSignature(r, sValue, &v).(*xecdsa.RSVSignature), nil
+}
@@ -0,0 +1,32 @@
+package contractcall
+
+import (
+	"os"
+
+	"github.com/donutnomad/blockchain-alg/xecdsa"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// NewKeystoreSigner decrypts a Web3 Secret Storage (v3) keystore JSON with passphrase.
+func NewKeystoreSigner(keyJSON []byte, passphrase string) (*EcdsaPrivateKeySigner, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt keystore")
+	}
+	privateKey, err := xecdsa.NewPrivateKeyS256([32]byte(crypto.FromECDSA(key.PrivateKey)))
+	if err != nil {
+		return nil, err
+	}
+	return NewEcdsaPrivateKeySigner(privateKey), nil
+}
+
+// NewKeystoreSignerFromFile reads and decrypts the keystore file at path, see NewKeystoreSigner.
+func NewKeystoreSignerFromFile(path string, passphrase string) (*EcdsaPrivateKeySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeystoreSigner(keyJSON, passphrase)
+}
@@ -0,0 +1,197 @@
+package contractcall
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+func newTestSignerTx(t *testing.T, from common.Address) ITx {
+	t.Helper()
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	txWrapper, err := NewTxBuilder(t.Context(), big.NewInt(1)).
+		SetFrom(from).
+		SetTo(to, false).
+		SetNonce(1).
+		SetGasPrice(NewGasPrice(big.NewInt(1), big.NewInt(1), big.NewInt(2))).
+		SetGasLimit(21000).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txWrapper
+}
+
+func assertSender(t *testing.T, txWrapper ITx, want common.Address) {
+	t.Helper()
+	sender, err := ethTypes.Sender(ethTypes.LatestSignerForChainID(big.NewInt(1)), txWrapper.ToTransaction())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != want {
+		t.Fatalf("expected sender %s, got %s", want, sender)
+	}
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}
+	keyJSON, err := keystore.EncryptKey(key, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewKeystoreSigner(keyJSON, "wrong"); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+	signer, err := NewKeystoreSigner(keyJSON, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address() != key.Address {
+		t.Fatalf("expected address %s, got %s", key.Address, signer.Address())
+	}
+	txWrapper := newTestSignerTx(t, key.Address)
+	if err := txWrapper.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	assertSender(t, txWrapper, key.Address)
+}
+
+func TestHTTPSigner(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req HTTPSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Address != address {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		sig, err := crypto.Sign(req.Hash, privateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(HTTPSignResponse{Signature: sig})
+	}))
+	defer server.Close()
+
+	signer := NewHTTPSigner(server.URL, address)
+	txWrapper := newTestSignerTx(t, address)
+	if err := txWrapper.Sign(signer); err == nil {
+		t.Fatal("expected an error without the authorization header")
+	}
+	signer.Header.Set("Authorization", "Bearer token")
+	if err := txWrapper.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	assertSender(t, txWrapper, address)
+
+	other := NewHTTPSigner(server.URL, common.HexToAddress("0x00000000000000000000000000000000000000cc"))
+	other.Header.Set("Authorization", "Bearer token")
+	if _, err := other.Sign(txWrapper.SigHash().Bytes()); err == nil {
+		t.Fatal("expected an error for an unknown address")
+	}
+}
+
+func TestParseRSVSignature_HighS(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	hash := crypto.Keccak256([]byte("high s"))
+	sig, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same signature with s' = N-s and the other recovery id
+	n := crypto.S256().Params().N
+	highS := new(big.Int).Sub(n, new(big.Int).SetBytes(sig[32:64]))
+	malleable := append([]byte{}, sig...)
+	highS.FillBytes(malleable[32:64])
+	malleable[64] = 27 + (sig[64] ^ 1)
+
+	parsed, err := parseRSVSignature(address, hash, malleable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.S().Cmp(new(big.Int).SetBytes(sig[32:64])) != 0 || parsed.V() != 27+sig[64] {
+		t.Fatalf("expected the low s form, got s=%s v=%d", parsed.S(), parsed.V())
+	}
+}
+
+type clefService struct {
+	key    *keystore.Key
+	tamper bool
+}
+
+func (s *clefService) List() []common.Address {
+	return []common.Address{s.key.Address}
+}
+
+func (s *clefService) SignTransaction(args apitypes.SendTxArgs) (map[string]any, error) {
+	if s.tamper {
+		args.Nonce++
+	}
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := ethTypes.SignTx(tx, ethTypes.LatestSignerForChainID(args.ChainID.ToInt()), s.key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := signed.MarshalBinary()
+	return map[string]any{"raw": hexutil.Bytes(raw), "tx": signed}, nil
+}
+
+func TestClefSigner(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	service := &clefService{key: &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}}
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", service); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	address := service.key.Address
+	signer := NewClefSigner(client, address)
+	accounts, err := signer.Accounts(t.Context())
+	if err != nil || len(accounts) != 1 || accounts[0] != address {
+		t.Fatalf("unexpected accounts %v (%v)", accounts, err)
+	}
+	if _, err := signer.Sign(make([]byte, 32)); !errors.Is(err, ErrSignHashUnsupported) {
+		t.Fatalf("expected ErrSignHashUnsupported, got %v", err)
+	}
+
+	txWrapper := newTestSignerTx(t, address)
+	if err := txWrapper.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	assertSender(t, txWrapper, address)
+
+	service.tamper = true
+	txWrapper = newTestSignerTx(t, address)
+	if err := txWrapper.Sign(signer); !errors.Is(err, ErrSignerModifiedTx) {
+		t.Fatalf("expected ErrSignerModifiedTx, got %v", err)
+	}
+	if v, r, s := txWrapper.ToTransaction().RawSignatureValues(); v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0 {
+		t.Fatal("signature of a modified transaction must not be kept")
+	}
+}
@@ -199,6 +199,9 @@ func (t *txImpl) SigHash() common.Hash {
 }
 
 func (t *txImpl) Sign(privateKey ISigner) error {
+	if txSigner, ok := privateKey.(ITxSigner); ok {
+		return t.signTx(txSigner)
+	}
 	sig, err := privateKey.Sign(t.SigHash().Bytes())
 	if err != nil {
 		return err
@@ -214,6 +217,24 @@ func (t *txImpl) Sign(privateKey ISigner) error {
 	return nil
 }
 
+func (t *txImpl) signTx(signer ITxSigner) error {
+	signed, err := signer.SignTx(t.ToTransaction(), t.ChainID())
+	if err != nil {
+		return err
+	}
+	if signed == nil {
+		return nil
+	}
+	oldV, oldR, oldS := t.Signature()
+	t.SetSignature(signed.RawSignatureValues())
+	// the signer must not change any field of the transaction
+	if t.Hash() != signed.Hash() {
+		t.SetSignature(oldV, oldR, oldS)
+		return ErrSignerModifiedTx
+	}
+	return nil
+}
+
 func (t *txImpl) BuildRlpFields(forSignature bool) []any {
 	return buildArgs(
 		if_(t.isModern(), t.chainID),
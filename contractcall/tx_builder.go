@@ -9,6 +9,8 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/pkg/errors"
 )
 
@@ -25,7 +27,13 @@ type TxBuilder struct {
 	gasPrice *GasPrice
 	gasLimit *big.Int
 
+	accessList       ethTypes.AccessList             // eip-2930
+	sidecar          *ethTypes.BlobTxSidecar         // eip-4844(blob)
+	maxFeePerBlobGas *big.Int                        // eip-4844(blob)
+	authList         []ethTypes.SetCodeAuthorization // eip-7702(auth)
+
 	checkContract bool
+	nonceTracker  INonceTracker // set by SetNonceBy when the nonce manager tracks nonces locally
 	err           error
 }
 
@@ -137,6 +145,7 @@ func (b *TxBuilder) SetGasLimit(gasLimit uint64) *TxBuilder {
 func (b *TxBuilder) SetNonce(nonce uint64) *TxBuilder {
 	return b.setField(func(b *TxBuilder) {
 		b.nonce = &nonce
+		b.nonceTracker = nil
 	})
 }
 
@@ -155,6 +164,144 @@ func (b *TxBuilder) SetNonceBy(transactor IGetNonce) *TxBuilder {
 		return b
 	}
 	b.nonce = &nonce
+	b.nonceTracker, _ = transactor.(INonceTracker)
+	return b
+}
+
+// releaseNonce gives the nonce back to the nonce manager it came from, if that manager tracks nonces.
+func (b *TxBuilder) releaseNonce(cause error) {
+	if b.nonceTracker == nil || b.nonce == nil {
+		return
+	}
+	b.nonceTracker.Release(b.ctx, b.from, *b.nonce, cause)
+	b.nonceTracker = nil
+}
+
+// settleNonce tells the nonce manager what a failed broadcast means for the nonce and reports
+// whether the transaction counts as sent. The nonce is only given back when the node clearly
+// rejected the transaction. After "already known" the transaction is in the mempool, after a
+// timeout or transport failure it may be, so the nonce stays handed out and a later Resync
+// finds the gap if the node doesn't have it.
+func (b *TxBuilder) settleNonce(errSend error) bool {
+	switch {
+	case isAlreadyKnown(errSend):
+		b.confirmNonce()
+		return true
+	case isRejected(errSend):
+		// "replacement transaction underpriced" means another transaction holds the nonce,
+		// Release resyncs with the node instead of reusing it
+		b.releaseNonce(errSend)
+	default:
+		b.confirmNonce()
+	}
+	return false
+}
+
+// confirmNonce tells the nonce manager the nonce was broadcast, if that manager tracks nonces.
+func (b *TxBuilder) confirmNonce() {
+	if b.nonceTracker == nil || b.nonce == nil {
+		return
+	}
+	b.nonceTracker.Confirm(b.from, *b.nonce)
+	b.nonceTracker = nil
+}
+
+// SetBlobs attaches blobs, the transaction becomes a blob transaction.
+// The KZG commitments and proofs are computed with DefaultBlobSidecarVersion.
+func (b *TxBuilder) SetBlobs(blobs []kzg4844.Blob) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+	sidecar, err := NewBlobSidecar(DefaultBlobSidecarVersion, blobs)
+	if err != nil {
+		b.err = errors.WithMessage(err, "failed to compute blob commitments")
+		return b
+	}
+	return b.SetBlobSidecar(sidecar)
+}
+
+// SetBlobData packs data into blobs with EncodeBlobs and attaches them.
+func (b *TxBuilder) SetBlobData(data []byte) *TxBuilder {
+	return b.SetBlobs(EncodeBlobs(data))
+}
+
+// SetBlobSidecar attaches a sidecar with precomputed commitments and proofs.
+func (b *TxBuilder) SetBlobSidecar(sidecar *ethTypes.BlobTxSidecar) *TxBuilder {
+	return b.setField(func(b *TxBuilder) {
+		b.sidecar = sidecar
+	})
+}
+
+// SetMaxFeePerBlobGas sets the blob fee cap
+func (b *TxBuilder) SetMaxFeePerBlobGas(maxFeePerBlobGas *big.Int) *TxBuilder {
+	return b.setField(func(b *TxBuilder) {
+		b.maxFeePerBlobGas = maxFeePerBlobGas
+	})
+}
+
+// SetMaxFeePerBlobGasBy sets the blob fee cap to twice the current blob base fee,
+// leaving room for the blob base fee to rise over the next few blocks.
+func (b *TxBuilder) SetMaxFeePerBlobGasBy(client IBlobBaseFee) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+	blobBaseFee, err := client.BlobBaseFee(b.ctx)
+	if err != nil {
+		b.err = errors.Wrap(EthereumRPCErr, err.Error())
+		return b
+	}
+	fee := new(big.Int).Mul(blobBaseFee, big.NewInt(2))
+	if fee.Sign() == 0 {
+		fee.SetInt64(1)
+	}
+	return b.SetMaxFeePerBlobGas(fee)
+}
+
+// blobFee returns the maximum blob fee of the transaction, nil if it carries no blobs.
+func (b *TxBuilder) blobFee() *big.Int {
+	if b.sidecar == nil || b.maxFeePerBlobGas == nil {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(BlobGas(len(b.sidecar.Blobs))), b.maxFeePerBlobGas)
+}
+
+// SetAuthList sets signed EIP-7702 authorizations, the transaction becomes a set-code transaction.
+func (b *TxBuilder) SetAuthList(authList []ethTypes.SetCodeAuthorization) *TxBuilder {
+	return b.setField(func(b *TxBuilder) {
+		b.authList = authList
+	})
+}
+
+// AddAuthorization signs an authorization delegating authority's account to delegate for the
+// builder's chain and appends it. The nonce is computed with AuthorizationNonce, so the nonce
+// of the transaction must be set first when authority is the sender; nonces is only used when it isn't.
+func (b *TxBuilder) AddAuthorization(authority ISigner, delegate common.Address, nonces IPendingNonceAt) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+	if b.chainId == nil {
+		b.err = errors.Wrap(TxBuilderMissingRequiredFieldErr, "chain id is required")
+		return b
+	}
+	if authority.Address() == b.from && b.nonce == nil {
+		b.err = errors.Wrap(TxBuilderMissingRequiredFieldErr, "nonce is required")
+		return b
+	}
+	var txNonce uint64
+	if b.nonce != nil {
+		txNonce = *b.nonce
+	}
+	nonce, err := AuthorizationNonce(b.ctx, nonces, b.from, txNonce, authority.Address(), b.authList)
+	if err != nil {
+		b.err = errors.WithMessage(err, "failed to get authorization nonce")
+		return b
+	}
+	auth, err := SignAuthorization(authority, b.chainId, delegate, nonce)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.authList = append(b.authList, auth)
 	return b
 }
 
@@ -181,11 +328,69 @@ func (b *TxBuilder) SetGasLimitBy(estimator IEstimateGas) *TxBuilder {
 		return b
 	}
 
+	gasLimit, err := estimator.EstimateGas(b.ctx, b.chainId, b.callMsg())
+	if err != nil {
+		b.err = &EstimateGasError{Err: err}
+		return b
+	}
+	b.gasLimit = gasLimit
+	return b
+}
+
+// SetAccessList sets the EIP-2930 access list. A legacy gas price builds an AccessListTxType transaction.
+func (b *TxBuilder) SetAccessList(accessList ethTypes.AccessList) *TxBuilder {
+	return b.setField(func(b *TxBuilder) {
+		b.accessList = accessList
+	})
+}
+
+// SetAccessListBy fetches an access list and keeps it only if it lowers the gas used.
+// Both variants are estimated with estimator, the gas limit of the cheaper one is set.
+func (b *TxBuilder) SetAccessListBy(creator IAccessListCreator, estimator IEstimateGas) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.err = b.checkRequiredFields1()
+	if b.err != nil {
+		return b
+	}
+	b.accessList = nil
+	msg := b.callMsg()
+	gasWithout, err := estimator.EstimateGas(b.ctx, b.chainId, msg)
+	if err != nil {
+		b.err = &EstimateGasError{Err: err}
+		return b
+	}
+	b.gasLimit = gasWithout
+
+	accessList, err := creator.CreateAccessList(b.ctx, b.chainId, msg)
+	if err != nil {
+		b.err = errors.WithMessage(err, "failed to create access list")
+		return b
+	}
+	if len(accessList) == 0 {
+		return b
+	}
+	msg.AccessList = accessList
+	gasWith, err := estimator.EstimateGas(b.ctx, b.chainId, msg)
+	if err != nil {
+		// the transaction works without the list
+		return b
+	}
+	if gasWith.Cmp(gasWithout) < 0 {
+		b.accessList = accessList
+		b.gasLimit = gasWith
+	}
+	return b
+}
+
+func (b *TxBuilder) callMsg() ethereum.CallMsg {
 	msg := ethereum.CallMsg{
-		From:  b.from,
-		To:    b.to,
-		Data:  b.data,
-		Value: b.value,
+		From:       b.from,
+		To:         b.to,
+		Data:       b.data,
+		Value:      b.value,
+		AccessList: b.accessList,
 	}
 
 	if b.gasPrice.LegacyGas != nil {
@@ -194,14 +399,12 @@ func (b *TxBuilder) SetGasLimitBy(estimator IEstimateGas) *TxBuilder {
 		msg.GasTipCap = b.gasPrice.DynamicGas.MaxPriorityFeePerGas
 		msg.GasFeeCap = b.gasPrice.DynamicGas.MaxFeePerGas
 	}
-
-	gasLimit, err := estimator.EstimateGas(b.ctx, b.chainId, msg)
-	if err != nil {
-		b.err = &EstimateGasError{Err: err}
-		return b
+	if b.sidecar != nil {
+		msg.BlobHashes = b.sidecar.BlobHashes()
+		msg.BlobGasFeeCap = b.maxFeePerBlobGas
 	}
-	b.gasLimit = gasLimit
-	return b
+	msg.AuthorizationList = b.authList
+	return msg
 }
 
 func (b *TxBuilder) BalanceCheck(checker IBalanceChecker) *TxBuilder {
@@ -215,7 +418,12 @@ func (b *TxBuilder) BalanceCheck(checker IBalanceChecker) *TxBuilder {
 	if checker == nil {
 		return b
 	}
-	err := checker.CheckBalance(b.ctx, b.from, b.data, b.to, b.gasPrice)
+	var err error
+	if blobChecker, ok := checker.(IBlobBalanceChecker); ok && b.blobFee() != nil {
+		err = blobChecker.CheckBalanceWithBlobFee(b.ctx, b.from, b.data, b.to, b.gasPrice, b.blobFee())
+	} else {
+		err = checker.CheckBalance(b.ctx, b.from, b.data, b.to, b.gasPrice)
+	}
 	if err != nil {
 		b.err = err
 		return b
@@ -290,14 +498,52 @@ func (b *TxBuilder) BuildTx(txType TxType) (ITx, error) {
 	impl.SetData(b.data)
 	impl.SetNonce(*b.nonce)
 	impl.SetGas(b.gasLimit.Uint64())
+	if len(b.accessList) > 0 && !impl.SetAccessList(b.accessList) {
+		b.err = errors.New("legacy transactions can't carry an access list")
+		return nil, b.err
+	}
+	if txType == BlobTxType {
+		if b.to == nil {
+			b.err = errors.Wrap(TxBuilderMissingRequiredFieldErr, "to is required")
+			return nil, b.err
+		}
+		if b.sidecar == nil || len(b.sidecar.Blobs) == 0 {
+			b.err = errors.Wrap(TxBuilderMissingRequiredFieldErr, "blobs are required")
+			return nil, b.err
+		}
+		if b.maxFeePerBlobGas == nil {
+			b.err = errors.Wrap(TxBuilderMissingRequiredFieldErr, "max fee per blob gas is required")
+			return nil, b.err
+		}
+		impl.SetBlobHashes(b.sidecar.BlobHashes())
+		impl.SetMaxFeePerBlobGas(b.maxFeePerBlobGas)
+		impl.SetSidecar(b.sidecar)
+	}
+	if txType == SetCodeTxType {
+		if b.to == nil {
+			b.err = errors.Wrap(TxBuilderMissingRequiredFieldErr, "to is required")
+			return nil, b.err
+		}
+		if len(b.authList) == 0 {
+			b.err = errors.Wrap(TxBuilderMissingRequiredFieldErr, "authorization list is required")
+			return nil, b.err
+		}
+		impl.SetAuthList(b.authList)
+	}
 	return impl, nil
 }
 
 // Build builds and returns the transaction
 func (b *TxBuilder) Build() (ITx, error) {
 	txType := LegacyTxType
-	if b.gasPrice != nil && b.gasPrice.DynamicGas != nil {
+	if b.sidecar != nil {
+		txType = BlobTxType
+	} else if len(b.authList) > 0 {
+		txType = SetCodeTxType
+	} else if b.gasPrice != nil && b.gasPrice.DynamicGas != nil {
 		txType = DynamicFeeTxType
+	} else if len(b.accessList) > 0 {
+		txType = AccessListTxType
 	}
 	return b.BuildTx(txType)
 }
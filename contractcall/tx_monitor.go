@@ -0,0 +1,376 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+var ErrTxDropped = errors.New("transaction nonce was used by another transaction")
+
+type ITransactionReceipt interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error)
+}
+
+type ITxMonitorClient interface {
+	ITransactionReceipt
+	ethereum.BlockNumberReader
+	ethereum.TransactionSender
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+type TxMonitorEventType int
+
+const (
+	// TxEventReplaced a stuck transaction was re-priced and broadcast again. Err is set when the
+	// broadcast failed without the node rejecting it (e.g. a timeout), the replacement is watched anyway.
+	TxEventReplaced TxMonitorEventType = iota
+	// TxEventMined one of the broadcast transactions has a receipt (successful or not).
+	TxEventMined
+	// TxEventFeeCapReached the transaction can't be re-priced without exceeding the fee cap,
+	// it is still watched until one of its hashes is mined or its nonce is used.
+	TxEventFeeCapReached
+	// TxEventReplaceFailed broadcasting a replacement failed, it is retried on the next check.
+	TxEventReplaceFailed
+	// TxEventDropped the nonce was consumed by a transaction this monitor doesn't know.
+	TxEventDropped
+	// TxEventUnderpriced the node rejected the largest bump of BumpPercents as underpriced,
+	// the transaction is still watched until one of its hashes is mined or its nonce is used, but no longer re-priced.
+	TxEventUnderpriced
+)
+
+// TxMonitorEvent reports what happened to a monitored transaction.
+// Original identifies the intent, Tx is the transaction the event is about.
+type TxMonitorEvent struct {
+	Type     TxMonitorEventType
+	Original common.Hash
+	Tx       *ethTypes.Transaction
+	Receipt  *ethTypes.Receipt
+	Err      error
+}
+
+type TxMonitorConfig struct {
+	// PollInterval defaults to 3 seconds.
+	PollInterval time.Duration
+	// AfterBlocks re-prices a transaction without receipt N blocks after its last broadcast, 0 disables it.
+	AfterBlocks uint64
+	// AfterDuration re-prices a transaction without receipt this long after its last broadcast, 0 disables it.
+	AfterDuration time.Duration
+	// BumpPercents is the escalation curve, the n-th replacement raises the fees of the previous
+	// broadcast by BumpPercents[n], the last entry is used for all further replacements.
+	// A replacement rejected as underpriced is retried with the next entry.
+	// Defaults to DefaultPriceBumpPercent.
+	BumpPercents []int64
+	// FeeCap is the hard cap for MaxFeePerGas (GasPrice for legacy transactions), nil means no cap.
+	FeeCap *big.Int
+	// GasPricer is optional, replacements never go below the current market price when set.
+	GasPricer IGasPricer
+	// OnEvent is called for every replacement and final outcome by the goroutine running Check,
+	// after the transaction is unlocked, so it may call the methods of the MonitoredTx.
+	OnEvent func(event TxMonitorEvent)
+}
+
+// TxMonitor watches broadcast transactions and re-prices the ones that are stuck.
+//
+//	monitor := NewTxMonitor(client, TxMonitorConfig{AfterBlocks: 5, BumpPercents: []int64{15, 25, 50}, FeeCap: maxFee})
+//	go monitor.Run(ctx)
+//	tracked, err := monitor.Track(ctx, tx, payer)
+//	receipt, err := tracked.Wait(ctx)
+type TxMonitor struct {
+	client ITxMonitorClient
+	config TxMonitorConfig
+	now    func() time.Time
+
+	mu      sync.Mutex
+	tracked map[common.Hash]*MonitoredTx
+}
+
+func NewTxMonitor(client ITxMonitorClient, config TxMonitorConfig) *TxMonitor {
+	if config.PollInterval == 0 {
+		config.PollInterval = 3 * time.Second
+	}
+	if len(config.BumpPercents) == 0 {
+		config.BumpPercents = []int64{DefaultPriceBumpPercent}
+	}
+	return &TxMonitor{
+		client:  client,
+		config:  config,
+		now:     time.Now,
+		tracked: make(map[common.Hash]*MonitoredTx),
+	}
+}
+
+// MonitoredTx is a transaction intent watched by TxMonitor.
+type MonitoredTx struct {
+	payer    ISigner
+	original common.Hash
+
+	mu       sync.Mutex
+	current  ITx
+	sent     []*ethTypes.Transaction // oldest first
+	attempts int
+	// underpriced counts the replacements rejected as underpriced since the last broadcast
+	underpriced int
+	sentBlock   uint64
+	sentAt      time.Time
+	capped      bool
+	nonceUsed   bool
+	receipt     *ethTypes.Receipt
+	err         error
+	done        chan struct{}
+}
+
+// Original returns the hash of the first broadcast transaction.
+func (m *MonitoredTx) Original() common.Hash {
+	return m.original
+}
+
+// Hashes returns all broadcast hashes, oldest first.
+func (m *MonitoredTx) Hashes() []common.Hash {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hashes := make([]common.Hash, len(m.sent))
+	for i, tx := range m.sent {
+		hashes[i] = tx.Hash()
+	}
+	return hashes
+}
+
+// Current returns the most recently broadcast transaction.
+func (m *MonitoredTx) Current() *ethTypes.Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current.ToTransaction()
+}
+
+// Done is closed when the transaction is mined or dropped.
+func (m *MonitoredTx) Done() <-chan struct{} {
+	return m.done
+}
+
+// Result returns the receipt of the mined transaction. It is only valid after Done is closed.
+func (m *MonitoredTx) Result() (*ethTypes.Receipt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.receipt, m.err
+}
+
+// Wait blocks until the transaction is mined or dropped. A reverted transaction returns its receipt without error.
+func (m *MonitoredTx) Wait(ctx context.Context) (*ethTypes.Receipt, error) {
+	select {
+	case <-m.done:
+		return m.Result()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Track starts watching tx, which must already be broadcast. payer signs the replacements.
+// The current block is read to count AfterBlocks from the broadcast.
+func (t *TxMonitor) Track(ctx context.Context, tx *ethTypes.Transaction, payer ISigner) (*MonitoredTx, error) {
+	return t.TrackTx(ctx, NewTx(tx, tx.ChainId()), payer)
+}
+
+// TrackTx is Track for an ITx.
+func (t *TxMonitor) TrackTx(ctx context.Context, tx ITx, payer ISigner) (*MonitoredTx, error) {
+	blockNumber, err := t.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", EthereumRPCErr, err)
+	}
+	sent := tx.ToTransaction()
+	hash := sent.Hash()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m, ok := t.tracked[hash]; ok {
+		return m, nil
+	}
+	m := &MonitoredTx{
+		payer:     payer,
+		original:  hash,
+		current:   tx,
+		sent:      []*ethTypes.Transaction{sent},
+		sentBlock: blockNumber,
+		sentAt:    t.now(),
+		done:      make(chan struct{}),
+	}
+	t.tracked[hash] = m
+	return m, nil
+}
+
+// Untrack stops watching the intent started by original without resolving it.
+func (t *TxMonitor) Untrack(original common.Hash) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.tracked, original)
+}
+
+// Len returns the number of transactions being watched.
+func (t *TxMonitor) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.tracked)
+}
+
+// Run calls Check every PollInterval until ctx is done.
+func (t *TxMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(t.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		// rpc failures are transient, the next tick retries
+		_ = t.Check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Check looks up the receipts of all watched transactions once and re-prices the stuck ones.
+func (t *TxMonitor) Check(ctx context.Context) error {
+	blockNumber, err := t.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", EthereumRPCErr, err)
+	}
+	t.mu.Lock()
+	list := make([]*MonitoredTx, 0, len(t.tracked))
+	for _, m := range t.tracked {
+		list = append(list, m)
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, m := range list {
+		event, err := t.check(ctx, m, blockNumber)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if event == nil {
+			continue
+		}
+		if event.Type == TxEventMined || event.Type == TxEventDropped {
+			t.Untrack(m.original)
+		}
+		// OnEvent runs without locks, it may call back into the monitor and m
+		t.emit(*event)
+	}
+	return errors.Join(errs...)
+}
+
+// check updates m and returns what happened to it, if anything.
+func (t *TxMonitor) check(ctx context.Context, m *MonitoredTx, blockNumber uint64) (*TxMonitorEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// any of the broadcast transactions may have been mined
+	for _, tx := range slices.Backward(m.sent) {
+		receipt, err := t.client.TransactionReceipt(ctx, tx.Hash())
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %w", EthereumRPCErr, err)
+		}
+		return m.finish(tx, receipt, nil), nil
+	}
+	if m.nonceUsed {
+		return m.finish(m.current.ToTransaction(), nil, ErrTxDropped), nil
+	}
+	if m.capped {
+		// no more replacements whose "nonce too low" would tell that the nonce was used
+		nonce, err := t.client.NonceAt(ctx, m.payer.Address(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", EthereumRPCErr, err)
+		}
+		// resolved on the next check, after the receipts had another chance to show up
+		m.nonceUsed = nonce > m.current.Nonce()
+		return nil, nil
+	}
+	if !t.isStuck(m, blockNumber) {
+		return nil, nil
+	}
+
+	step := m.attempts + m.underpriced
+	bump := t.config.BumpPercents[min(step, len(t.config.BumpPercents)-1)]
+	tx, err := SpeedUp(ctx, t.client, m.current, m.payer, ReplaceTxOption{
+		BumpPercent: bump,
+		GasPricer:   t.config.GasPricer,
+		FeeCap:      t.config.FeeCap,
+	})
+	var errSend *SendTransactionError
+	sendFailed := errors.As(err, &errSend)
+	switch {
+	case errors.Is(err, ErrReplaceFeeCapExceeded):
+		m.capped = true
+		return &TxMonitorEvent{Type: TxEventFeeCapReached, Original: m.original, Tx: m.current.ToTransaction(), Err: err}, nil
+	case isNonceTooLow(err):
+		// Mined by one of our hashes (its receipt shows up on the next check) or by an unknown transaction.
+		m.nonceUsed = true
+		return nil, nil
+	case isReplacementUnderpriced(err):
+		// the node wants a larger bump than the curve gave, retry with the next entry or give up
+		if step >= len(t.config.BumpPercents)-1 {
+			m.capped = true
+			return &TxMonitorEvent{Type: TxEventUnderpriced, Original: m.original, Tx: m.current.ToTransaction(), Err: err}, nil
+		}
+		m.underpriced++
+		return &TxMonitorEvent{Type: TxEventReplaceFailed, Original: m.original, Tx: m.current.ToTransaction(), Err: err}, nil
+	case sendFailed && isAlreadyKnown(err):
+		// the node already has this replacement, e.g. an earlier broadcast timed out after being accepted
+		tx, err = errSend.Tx, nil
+	case sendFailed && !isRejected(err):
+		// timeout or transport failure, the node may have the replacement and mine it
+		tx = errSend.Tx
+	case err != nil:
+		return &TxMonitorEvent{Type: TxEventReplaceFailed, Original: m.original, Tx: m.current.ToTransaction(), Err: err}, err
+	}
+	m.current = NewTx(tx, tx.ChainId())
+	m.current.SetSidecar(tx.BlobTxSidecar())
+	m.sent = append(m.sent, tx)
+	m.attempts++
+	m.underpriced = 0
+	m.sentBlock = blockNumber
+	m.sentAt = t.now()
+	return &TxMonitorEvent{Type: TxEventReplaced, Original: m.original, Tx: tx, Err: err}, nil
+}
+
+func (t *TxMonitor) isStuck(m *MonitoredTx, blockNumber uint64) bool {
+	if t.config.AfterBlocks > 0 && blockNumber >= m.sentBlock+t.config.AfterBlocks {
+		return true
+	}
+	if t.config.AfterDuration > 0 && t.now().Sub(m.sentAt) >= t.config.AfterDuration {
+		return true
+	}
+	return false
+}
+
+// finish resolves m and returns the final event, the caller holds m.mu.
+func (m *MonitoredTx) finish(tx *ethTypes.Transaction, receipt *ethTypes.Receipt, err error) *TxMonitorEvent {
+	m.receipt = receipt
+	m.err = err
+	close(m.done)
+
+	event := &TxMonitorEvent{Type: TxEventMined, Original: m.original, Tx: tx, Receipt: receipt, Err: err}
+	if err != nil {
+		event.Type = TxEventDropped
+	}
+	return event
+}
+
+func (t *TxMonitor) emit(event TxMonitorEvent) {
+	if t.config.OnEvent != nil {
+		t.config.OnEvent(event)
+	}
+}
+
+func isNonceTooLow(err error) bool {
+	evmErr, ok := asEvmError(err)
+	return ok && evmErr.IsNonceTooLow()
+}
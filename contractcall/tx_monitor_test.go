@@ -0,0 +1,361 @@
+package contractcall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+type fakeMonitorClient struct {
+	mu       sync.Mutex
+	block    uint64
+	sent     []*ethTypes.Transaction
+	receipts map[common.Hash]*ethTypes.Receipt
+	sendErr  error
+	nonce    uint64
+}
+
+func (f *fakeMonitorClient) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.nonce, nil
+}
+
+func (f *fakeMonitorClient) BlockNumber(context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.block, nil
+}
+
+func (f *fakeMonitorClient) TransactionReceipt(_ context.Context, txHash common.Hash) (*ethTypes.Receipt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r, ok := f.receipts[txHash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeMonitorClient) SendTransaction(_ context.Context, tx *ethTypes.Transaction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.sent = append(f.sent, tx)
+	return nil
+}
+
+func (f *fakeMonitorClient) mine(hash common.Hash) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.receipts[hash] = &ethTypes.Receipt{TxHash: hash, Status: ethTypes.ReceiptStatusSuccessful}
+}
+
+func (f *fakeMonitorClient) advance(blocks uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.block += blocks
+}
+
+type testJsonError struct{ msg string }
+
+func (e testJsonError) Error() string  { return e.msg }
+func (e testJsonError) ErrorCode() int { return -32000 }
+func (e testJsonError) ErrorData() any { return nil }
+
+func newMonitorTestTx(t *testing.T, signer ISigner) ITx {
+	t.Helper()
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	tx := NewTxWith(DynamicFeeTxType, 1)
+	tx.SetNonce(1)
+	tx.SetTo(&to)
+	tx.SetGas(21000)
+	tx.SetMaxPriorityFeePerGas(big.NewInt(100))
+	tx.SetMaxFeePerGas(big.NewInt(1_000))
+	if err := tx.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func TestTxMonitor_Escalation(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &fakeMonitorClient{block: 100, receipts: map[common.Hash]*ethTypes.Receipt{}}
+	var events []TxMonitorEvent
+	monitor := NewTxMonitor(client, TxMonitorConfig{
+		AfterBlocks:  2,
+		BumpPercents: []int64{20, 50},
+		OnEvent:      func(event TxMonitorEvent) { events = append(events, event) },
+	})
+	ctx := t.Context()
+	tracked, err := monitor.TrackTx(ctx, newMonitorTestTx(t, signer), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.sent) != 0 {
+		t.Fatal("replaced a transaction that is not stuck yet")
+	}
+
+	wantFees := []int64{1_200, 1_800, 2_700} // 20%, then 50% repeated
+	for _, want := range wantFees {
+		client.advance(2)
+		if err := monitor.Check(ctx); err != nil {
+			t.Fatal(err)
+		}
+		last := client.sent[len(client.sent)-1]
+		if last.GasFeeCap().Int64() != want || last.Nonce() != 1 {
+			t.Fatalf("expected fee cap %d, got %s (nonce %d)", want, last.GasFeeCap(), last.Nonce())
+		}
+	}
+	if hashes := tracked.Hashes(); len(hashes) != 4 || hashes[0] != tracked.Original() {
+		t.Fatalf("unexpected hashes %v", hashes)
+	}
+
+	// an earlier broadcast gets mined
+	mined := tracked.Hashes()[1]
+	client.mine(mined)
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := tracked.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TxHash != mined || monitor.Len() != 0 {
+		t.Fatalf("expected receipt of %s, got %s", mined, receipt.TxHash)
+	}
+	last := events[len(events)-1]
+	if last.Type != TxEventMined || last.Tx.Hash() != mined || last.Original != tracked.Original() {
+		t.Fatalf("unexpected final event %+v", last)
+	}
+	if len(events) != 4 {
+		t.Fatalf("expected 3 replacements and 1 mined event, got %d events", len(events))
+	}
+}
+
+func TestTxMonitor_FeeCap(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &fakeMonitorClient{block: 1, receipts: map[common.Hash]*ethTypes.Receipt{}}
+	var events []TxMonitorEvent
+	monitor := NewTxMonitor(client, TxMonitorConfig{
+		AfterBlocks:  1,
+		BumpPercents: []int64{50},
+		FeeCap:       big.NewInt(1_300),
+		OnEvent:      func(event TxMonitorEvent) { events = append(events, event) },
+	})
+	ctx := t.Context()
+	if _, err := monitor.TrackTx(ctx, newMonitorTestTx(t, signer), signer); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = monitor.Check(ctx)
+	for range 3 {
+		client.advance(1)
+		if err := monitor.Check(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 1000 -> 1300 (clamped), then the 10% minimum is above the cap
+	if len(client.sent) != 1 || client.sent[0].GasFeeCap().Int64() != 1_300 {
+		t.Fatalf("expected one clamped replacement, got %d", len(client.sent))
+	}
+	if len(events) != 2 || events[1].Type != TxEventFeeCapReached || !errors.Is(events[1].Err, ErrReplaceFeeCapExceeded) {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if monitor.Len() != 1 {
+		t.Fatal("capped transaction must still be watched")
+	}
+
+	// the nonce is taken by a transaction the monitor doesn't know
+	client.mu.Lock()
+	client.nonce = 2
+	client.mu.Unlock()
+	_ = monitor.Check(ctx)
+	_ = monitor.Check(ctx)
+	if monitor.Len() != 0 || events[len(events)-1].Type != TxEventDropped {
+		t.Fatalf("expected the capped transaction to be dropped, got %+v", events[len(events)-1])
+	}
+}
+
+func TestTxMonitor_Dropped(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &fakeMonitorClient{block: 1, receipts: map[common.Hash]*ethTypes.Receipt{}}
+	monitor := NewTxMonitor(client, TxMonitorConfig{AfterBlocks: 1})
+	ctx := t.Context()
+	tracked, err := monitor.TrackTx(ctx, newMonitorTestTx(t, signer), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = monitor.Check(ctx)
+	client.sendErr = testJsonError{msg: "nonce too low: next nonce 2, tx nonce 1"}
+	client.advance(1)
+	_ = monitor.Check(ctx)
+	_ = monitor.Check(ctx)
+
+	if _, err := tracked.Result(); !errors.Is(err, ErrTxDropped) || monitor.Len() != 0 {
+		t.Fatalf("expected ErrTxDropped, got %v", err)
+	}
+}
+
+func TestTxMonitor_Underpriced(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &fakeMonitorClient{block: 1, receipts: map[common.Hash]*ethTypes.Receipt{}}
+	var events []TxMonitorEvent
+	monitor := NewTxMonitor(client, TxMonitorConfig{
+		AfterBlocks:  1,
+		BumpPercents: []int64{10, 30},
+		OnEvent:      func(event TxMonitorEvent) { events = append(events, event) },
+	})
+	ctx := t.Context()
+	if _, err := monitor.TrackTx(ctx, newMonitorTestTx(t, signer), signer); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first bump is rejected, the next check uses the next entry of the curve
+	client.sendErr = testJsonError{msg: "replacement transaction underpriced"}
+	client.advance(1)
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client.sendErr = nil
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.sent) != 1 || client.sent[0].GasFeeCap().Int64() != 1_300 {
+		t.Fatalf("expected one replacement with a 30%% bump, got %d", len(client.sent))
+	}
+
+	// the last entry is rejected too, the monitor stops re-pricing
+	client.sendErr = testJsonError{msg: "replacement transaction underpriced"}
+	client.advance(1)
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client.advance(1)
+	_ = monitor.Check(ctx)
+	types := make([]TxMonitorEventType, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	if !slices.Equal(types, []TxMonitorEventType{TxEventReplaceFailed, TxEventReplaced, TxEventUnderpriced}) {
+		t.Fatalf("unexpected events %v", types)
+	}
+	if monitor.Len() != 1 {
+		t.Fatal("underpriced transaction must still be watched")
+	}
+}
+
+func TestTxMonitor_AlreadyKnown(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &fakeMonitorClient{block: 1, receipts: map[common.Hash]*ethTypes.Receipt{}}
+	monitor := NewTxMonitor(client, TxMonitorConfig{AfterBlocks: 1})
+	ctx := t.Context()
+	tracked, err := monitor.TrackTx(ctx, newMonitorTestTx(t, signer), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.sendErr = testJsonError{msg: "already known"}
+	client.advance(1)
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	hashes := tracked.Hashes()
+	if len(hashes) != 2 || tracked.Current().Hash() != hashes[1] {
+		t.Fatalf("expected the known replacement to be watched, got %v", hashes)
+	}
+	client.mine(hashes[1])
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if receipt, err := tracked.Wait(ctx); err != nil || receipt.TxHash != hashes[1] {
+		t.Fatalf("expected receipt of %s, got %v %v", hashes[1], receipt, err)
+	}
+}
+
+func TestTxMonitor_AmbiguousBroadcast(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &fakeMonitorClient{block: 1, receipts: map[common.Hash]*ethTypes.Receipt{}}
+	var events []TxMonitorEvent
+	monitor := NewTxMonitor(client, TxMonitorConfig{
+		AfterBlocks: 1,
+		OnEvent:     func(event TxMonitorEvent) { events = append(events, event) },
+	})
+	ctx := t.Context()
+	tracked, err := monitor.TrackTx(ctx, newMonitorTestTx(t, signer), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the broadcast times out, but the node got the replacement and mines it
+	client.sendErr = context.DeadlineExceeded
+	client.advance(1)
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Type != TxEventReplaced || !errors.Is(events[0].Err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	hashes := tracked.Hashes()
+	if len(hashes) != 2 {
+		t.Fatalf("expected the replacement to be watched, got %v", hashes)
+	}
+	client.mine(hashes[1])
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if receipt, err := tracked.Result(); err != nil || receipt == nil || receipt.TxHash != hashes[1] {
+		t.Fatalf("expected receipt of %s, got %v %v", hashes[1], receipt, err)
+	}
+}
+
+func TestTxMonitor_EventCallsBack(t *testing.T) {
+	signer := newTestSigner(t)
+	client := &fakeMonitorClient{block: 1, receipts: map[common.Hash]*ethTypes.Receipt{}}
+	ctx := t.Context()
+	var tracked *MonitoredTx
+	var results []*ethTypes.Receipt
+	monitor := NewTxMonitor(client, TxMonitorConfig{
+		AfterBlocks: 1,
+		OnEvent: func(event TxMonitorEvent) {
+			// must not deadlock on the locks of the monitor or the transaction
+			_ = tracked.Hashes()
+			if event.Type == TxEventMined {
+				receipt, err := tracked.Result()
+				if err != nil {
+					t.Error(err)
+				}
+				results = append(results, receipt)
+			}
+		},
+	})
+	tracked, err := monitor.TrackTx(ctx, newMonitorTestTx(t, signer), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.advance(1)
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client.mine(tracked.Original())
+	if err := monitor.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].TxHash != tracked.Original() || monitor.Len() != 0 {
+		t.Fatalf("unexpected results %v", results)
+	}
+}
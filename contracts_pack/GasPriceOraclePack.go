@@ -0,0 +1,576 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts_pack
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// GasPriceOracleMetaData contains all meta data concerning the GasPriceOracle contract.
+var GasPriceOracleMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"baseFee\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"baseFeeScalar\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint32\",\"internalType\":\"uint32\"}]},{\"type\":\"function\",\"name\":\"blobBaseFee\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"blobBaseFeeScalar\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint32\",\"internalType\":\"uint32\"}]},{\"type\":\"function\",\"name\":\"decimals\",\"stateMutability\":\"pure\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"gasPrice\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getL1Fee\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"_data\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getL1FeeUpperBound\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"_unsignedTxSize\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getL1GasUsed\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"_data\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"isEcotone\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}]},{\"type\":\"function\",\"name\":\"isFjord\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}]},{\"type\":\"function\",\"name\":\"l1BaseFee\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"overhead\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"scalar\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"version\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}]}]",
+	ID:  "GasPriceOracle",
+}
+
+// GasPriceOracle is an auto generated Go binding around an Ethereum contract.
+type GasPriceOracle struct {
+	abi abi.ABI
+}
+
+// NewGasPriceOracle creates a new instance of GasPriceOracle.
+func NewGasPriceOracle() *GasPriceOracle {
+	parsed, err := GasPriceOracleMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &GasPriceOracle{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *GasPriceOracle) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackBaseFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x6ef25c3a.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function baseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackBaseFee() []byte {
+	enc, err := gasPriceOracle.abi.Pack("baseFee")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackBaseFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x6ef25c3a.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function baseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackBaseFee() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("baseFee")
+}
+
+// UnpackBaseFee is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x6ef25c3a.
+//
+// Solidity: function baseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackBaseFee(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("baseFee", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackBaseFeeScalar is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xc5985918.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function baseFeeScalar() view returns(uint32)
+func (gasPriceOracle *GasPriceOracle) PackBaseFeeScalar() []byte {
+	enc, err := gasPriceOracle.abi.Pack("baseFeeScalar")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackBaseFeeScalar is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xc5985918.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function baseFeeScalar() view returns(uint32)
+func (gasPriceOracle *GasPriceOracle) TryPackBaseFeeScalar() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("baseFeeScalar")
+}
+
+// UnpackBaseFeeScalar is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xc5985918.
+//
+// Solidity: function baseFeeScalar() view returns(uint32)
+func (gasPriceOracle *GasPriceOracle) UnpackBaseFeeScalar(data []byte) (uint32, error) {
+	out, err := gasPriceOracle.abi.Unpack("baseFeeScalar", data)
+	if err != nil {
+		return *new(uint32), err
+	}
+	out0 := *abi.ConvertType(out[0], new(uint32)).(*uint32)
+	return out0, nil
+}
+
+// PackBlobBaseFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf8206140.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function blobBaseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackBlobBaseFee() []byte {
+	enc, err := gasPriceOracle.abi.Pack("blobBaseFee")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackBlobBaseFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf8206140.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function blobBaseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackBlobBaseFee() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("blobBaseFee")
+}
+
+// UnpackBlobBaseFee is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xf8206140.
+//
+// Solidity: function blobBaseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackBlobBaseFee(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("blobBaseFee", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackBlobBaseFeeScalar is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x68d5dca6.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function blobBaseFeeScalar() view returns(uint32)
+func (gasPriceOracle *GasPriceOracle) PackBlobBaseFeeScalar() []byte {
+	enc, err := gasPriceOracle.abi.Pack("blobBaseFeeScalar")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackBlobBaseFeeScalar is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x68d5dca6.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function blobBaseFeeScalar() view returns(uint32)
+func (gasPriceOracle *GasPriceOracle) TryPackBlobBaseFeeScalar() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("blobBaseFeeScalar")
+}
+
+// UnpackBlobBaseFeeScalar is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x68d5dca6.
+//
+// Solidity: function blobBaseFeeScalar() view returns(uint32)
+func (gasPriceOracle *GasPriceOracle) UnpackBlobBaseFeeScalar(data []byte) (uint32, error) {
+	out, err := gasPriceOracle.abi.Unpack("blobBaseFeeScalar", data)
+	if err != nil {
+		return *new(uint32), err
+	}
+	out0 := *abi.ConvertType(out[0], new(uint32)).(*uint32)
+	return out0, nil
+}
+
+// PackDecimals is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x313ce567.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function decimals() pure returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackDecimals() []byte {
+	enc, err := gasPriceOracle.abi.Pack("decimals")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackDecimals is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x313ce567.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function decimals() pure returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackDecimals() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("decimals")
+}
+
+// UnpackDecimals is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x313ce567.
+//
+// Solidity: function decimals() pure returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackDecimals(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("decimals", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGasPrice is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xfe173b97.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function gasPrice() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackGasPrice() []byte {
+	enc, err := gasPriceOracle.abi.Pack("gasPrice")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGasPrice is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xfe173b97.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function gasPrice() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackGasPrice() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("gasPrice")
+}
+
+// UnpackGasPrice is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xfe173b97.
+//
+// Solidity: function gasPrice() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackGasPrice(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("gasPrice", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetL1Fee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x49948e0e.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getL1Fee(bytes _data) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackGetL1Fee(data []byte) []byte {
+	enc, err := gasPriceOracle.abi.Pack("getL1Fee", data)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetL1Fee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x49948e0e.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getL1Fee(bytes _data) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackGetL1Fee(data []byte) ([]byte, error) {
+	return gasPriceOracle.abi.Pack("getL1Fee", data)
+}
+
+// UnpackGetL1Fee is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x49948e0e.
+//
+// Solidity: function getL1Fee(bytes _data) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackGetL1Fee(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("getL1Fee", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetL1FeeUpperBound is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf1c7a58b.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getL1FeeUpperBound(uint256 _unsignedTxSize) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackGetL1FeeUpperBound(unsignedTxSize *big.Int) []byte {
+	enc, err := gasPriceOracle.abi.Pack("getL1FeeUpperBound", unsignedTxSize)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetL1FeeUpperBound is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf1c7a58b.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getL1FeeUpperBound(uint256 _unsignedTxSize) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackGetL1FeeUpperBound(unsignedTxSize *big.Int) ([]byte, error) {
+	return gasPriceOracle.abi.Pack("getL1FeeUpperBound", unsignedTxSize)
+}
+
+// UnpackGetL1FeeUpperBound is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xf1c7a58b.
+//
+// Solidity: function getL1FeeUpperBound(uint256 _unsignedTxSize) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackGetL1FeeUpperBound(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("getL1FeeUpperBound", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetL1GasUsed is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xde26c4a1.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getL1GasUsed(bytes _data) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackGetL1GasUsed(data []byte) []byte {
+	enc, err := gasPriceOracle.abi.Pack("getL1GasUsed", data)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetL1GasUsed is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xde26c4a1.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getL1GasUsed(bytes _data) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackGetL1GasUsed(data []byte) ([]byte, error) {
+	return gasPriceOracle.abi.Pack("getL1GasUsed", data)
+}
+
+// UnpackGetL1GasUsed is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xde26c4a1.
+//
+// Solidity: function getL1GasUsed(bytes _data) view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackGetL1GasUsed(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("getL1GasUsed", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackIsEcotone is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x4ef6e224.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function isEcotone() view returns(bool)
+func (gasPriceOracle *GasPriceOracle) PackIsEcotone() []byte {
+	enc, err := gasPriceOracle.abi.Pack("isEcotone")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackIsEcotone is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x4ef6e224.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function isEcotone() view returns(bool)
+func (gasPriceOracle *GasPriceOracle) TryPackIsEcotone() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("isEcotone")
+}
+
+// UnpackIsEcotone is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x4ef6e224.
+//
+// Solidity: function isEcotone() view returns(bool)
+func (gasPriceOracle *GasPriceOracle) UnpackIsEcotone(data []byte) (bool, error) {
+	out, err := gasPriceOracle.abi.Unpack("isEcotone", data)
+	if err != nil {
+		return *new(bool), err
+	}
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+	return out0, nil
+}
+
+// PackIsFjord is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x960e3a23.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function isFjord() view returns(bool)
+func (gasPriceOracle *GasPriceOracle) PackIsFjord() []byte {
+	enc, err := gasPriceOracle.abi.Pack("isFjord")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackIsFjord is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x960e3a23.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function isFjord() view returns(bool)
+func (gasPriceOracle *GasPriceOracle) TryPackIsFjord() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("isFjord")
+}
+
+// UnpackIsFjord is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x960e3a23.
+//
+// Solidity: function isFjord() view returns(bool)
+func (gasPriceOracle *GasPriceOracle) UnpackIsFjord(data []byte) (bool, error) {
+	out, err := gasPriceOracle.abi.Unpack("isFjord", data)
+	if err != nil {
+		return *new(bool), err
+	}
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+	return out0, nil
+}
+
+// PackL1BaseFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x519b4bd3.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function l1BaseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackL1BaseFee() []byte {
+	enc, err := gasPriceOracle.abi.Pack("l1BaseFee")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackL1BaseFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x519b4bd3.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function l1BaseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackL1BaseFee() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("l1BaseFee")
+}
+
+// UnpackL1BaseFee is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x519b4bd3.
+//
+// Solidity: function l1BaseFee() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackL1BaseFee(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("l1BaseFee", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackOverhead is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0c18c162.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function overhead() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackOverhead() []byte {
+	enc, err := gasPriceOracle.abi.Pack("overhead")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackOverhead is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0c18c162.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function overhead() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackOverhead() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("overhead")
+}
+
+// UnpackOverhead is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x0c18c162.
+//
+// Solidity: function overhead() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackOverhead(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("overhead", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackScalar is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf45e65d8.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function scalar() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) PackScalar() []byte {
+	enc, err := gasPriceOracle.abi.Pack("scalar")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackScalar is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf45e65d8.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function scalar() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) TryPackScalar() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("scalar")
+}
+
+// UnpackScalar is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xf45e65d8.
+//
+// Solidity: function scalar() view returns(uint256)
+func (gasPriceOracle *GasPriceOracle) UnpackScalar(data []byte) (*big.Int, error) {
+	out, err := gasPriceOracle.abi.Unpack("scalar", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackVersion is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x54fd4d50.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function version() view returns(string)
+func (gasPriceOracle *GasPriceOracle) PackVersion() []byte {
+	enc, err := gasPriceOracle.abi.Pack("version")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackVersion is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x54fd4d50.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function version() view returns(string)
+func (gasPriceOracle *GasPriceOracle) TryPackVersion() ([]byte, error) {
+	return gasPriceOracle.abi.Pack("version")
+}
+
+// UnpackVersion is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x54fd4d50.
+//
+// Solidity: function version() view returns(string)
+func (gasPriceOracle *GasPriceOracle) UnpackVersion(data []byte) (string, error) {
+	out, err := gasPriceOracle.abi.Unpack("version", data)
+	if err != nil {
+		return *new(string), err
+	}
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+	return out0, nil
+}
@@ -0,0 +1,145 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contracts_pack
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// NodeInterfaceMetaData contains all meta data concerning the NodeInterface contract.
+var NodeInterfaceMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"gasEstimateComponents\",\"stateMutability\":\"payable\",\"inputs\":[{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"contractCreation\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"data\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"gasEstimate\",\"type\":\"uint64\",\"internalType\":\"uint64\"},{\"name\":\"gasEstimateForL1\",\"type\":\"uint64\",\"internalType\":\"uint64\"},{\"name\":\"baseFee\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"l1BaseFeeEstimate\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"gasEstimateL1Component\",\"stateMutability\":\"payable\",\"inputs\":[{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"contractCreation\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"data\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[{\"name\":\"gasEstimateForL1\",\"type\":\"uint64\",\"internalType\":\"uint64\"},{\"name\":\"baseFee\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"l1BaseFeeEstimate\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}]",
+	ID:  "NodeInterface",
+}
+
+// NodeInterface is an auto generated Go binding around an Ethereum contract.
+type NodeInterface struct {
+	abi abi.ABI
+}
+
+// NewNodeInterface creates a new instance of NodeInterface.
+func NewNodeInterface() *NodeInterface {
+	parsed, err := NodeInterfaceMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &NodeInterface{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *NodeInterface) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackGasEstimateComponents is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xc94e6eeb.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function gasEstimateComponents(address to, bool contractCreation, bytes data) payable returns(uint64 gasEstimate, uint64 gasEstimateForL1, uint256 baseFee, uint256 l1BaseFeeEstimate)
+func (nodeInterface *NodeInterface) PackGasEstimateComponents(to common.Address, contractCreation bool, data []byte) []byte {
+	enc, err := nodeInterface.abi.Pack("gasEstimateComponents", to, contractCreation, data)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGasEstimateComponents is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xc94e6eeb.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function gasEstimateComponents(address to, bool contractCreation, bytes data) payable returns(uint64 gasEstimate, uint64 gasEstimateForL1, uint256 baseFee, uint256 l1BaseFeeEstimate)
+func (nodeInterface *NodeInterface) TryPackGasEstimateComponents(to common.Address, contractCreation bool, data []byte) ([]byte, error) {
+	return nodeInterface.abi.Pack("gasEstimateComponents", to, contractCreation, data)
+}
+
+// GasEstimateComponentsOutput serves as a container for the return parameters of contract
+// method GasEstimateComponents.
+type GasEstimateComponentsOutput struct {
+	GasEstimate       uint64
+	GasEstimateForL1  uint64
+	BaseFee           *big.Int
+	L1BaseFeeEstimate *big.Int
+}
+
+// UnpackGasEstimateComponents is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xc94e6eeb.
+//
+// Solidity: function gasEstimateComponents(address to, bool contractCreation, bytes data) payable returns(uint64 gasEstimate, uint64 gasEstimateForL1, uint256 baseFee, uint256 l1BaseFeeEstimate)
+func (nodeInterface *NodeInterface) UnpackGasEstimateComponents(data []byte) (GasEstimateComponentsOutput, error) {
+	out, err := nodeInterface.abi.Unpack("gasEstimateComponents", data)
+	outstruct := new(GasEstimateComponentsOutput)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.GasEstimate = *abi.ConvertType(out[0], new(uint64)).(*uint64)
+	outstruct.GasEstimateForL1 = *abi.ConvertType(out[1], new(uint64)).(*uint64)
+	outstruct.BaseFee = abi.ConvertType(out[2], new(big.Int)).(*big.Int)
+	outstruct.L1BaseFeeEstimate = abi.ConvertType(out[3], new(big.Int)).(*big.Int)
+	return *outstruct, nil
+}
+
+// PackGasEstimateL1Component is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x77d488a2.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function gasEstimateL1Component(address to, bool contractCreation, bytes data) payable returns(uint64 gasEstimateForL1, uint256 baseFee, uint256 l1BaseFeeEstimate)
+func (nodeInterface *NodeInterface) PackGasEstimateL1Component(to common.Address, contractCreation bool, data []byte) []byte {
+	enc, err := nodeInterface.abi.Pack("gasEstimateL1Component", to, contractCreation, data)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGasEstimateL1Component is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x77d488a2.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function gasEstimateL1Component(address to, bool contractCreation, bytes data) payable returns(uint64 gasEstimateForL1, uint256 baseFee, uint256 l1BaseFeeEstimate)
+func (nodeInterface *NodeInterface) TryPackGasEstimateL1Component(to common.Address, contractCreation bool, data []byte) ([]byte, error) {
+	return nodeInterface.abi.Pack("gasEstimateL1Component", to, contractCreation, data)
+}
+
+// GasEstimateL1ComponentOutput serves as a container for the return parameters of contract
+// method GasEstimateL1Component.
+type GasEstimateL1ComponentOutput struct {
+	GasEstimateForL1  uint64
+	BaseFee           *big.Int
+	L1BaseFeeEstimate *big.Int
+}
+
+// UnpackGasEstimateL1Component is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x77d488a2.
+//
+// Solidity: function gasEstimateL1Component(address to, bool contractCreation, bytes data) payable returns(uint64 gasEstimateForL1, uint256 baseFee, uint256 l1BaseFeeEstimate)
+func (nodeInterface *NodeInterface) UnpackGasEstimateL1Component(data []byte) (GasEstimateL1ComponentOutput, error) {
+	out, err := nodeInterface.abi.Unpack("gasEstimateL1Component", data)
+	outstruct := new(GasEstimateL1ComponentOutput)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.GasEstimateForL1 = *abi.ConvertType(out[0], new(uint64)).(*uint64)
+	outstruct.BaseFee = abi.ConvertType(out[1], new(big.Int)).(*big.Int)
+	outstruct.L1BaseFeeEstimate = abi.ConvertType(out[2], new(big.Int)).(*big.Int)
+	return *outstruct, nil
+}
@@ -0,0 +1,58 @@
+package ethclient
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type accessListService struct {
+	args  map[string]any
+	block string
+}
+
+func (s *accessListService) CreateAccessList(args map[string]any, block string) map[string]any {
+	s.args = args
+	s.block = block
+	return map[string]any{
+		"accessList": []map[string]any{{
+			"address":     "0x00000000000000000000000000000000000000bb",
+			"storageKeys": []string{"0x0000000000000000000000000000000000000000000000000000000000000001"},
+		}},
+		"gasUsed": "0x6b6c",
+		"error":   "execution reverted",
+	}
+}
+
+func TestCreateAccessList(t *testing.T) {
+	service := &accessListService{}
+	ec := newInProcClient(t, service)
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	res, err := ec.CreateAccessList(t.Context(), ethereum.CallMsg{To: &to, Data: []byte{1}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.block != "pending" || service.args["input"] != "0x01" {
+		t.Fatalf("unexpected request args=%v block=%s", service.args, service.block)
+	}
+	if res.GasUsed != 0x6b6c || res.Error != "execution reverted" || len(res.AccessList) != 1 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if res.AccessList[0].Address != ecommon.Address(to) || res.AccessList[0].StorageKeys[0] != ecommon.BigToHash(big.NewInt(1)) {
+		t.Fatalf("unexpected access list %+v", res.AccessList)
+	}
+	if list := res.AccessList.ToEthAccessList(); list[0].Address != to || list.StorageKeys() != 1 {
+		t.Fatalf("unexpected converted access list %+v", list)
+	}
+
+	if _, err := ec.CreateAccessList(t.Context(), ethereum.CallMsg{To: &to}, big.NewInt(16)); err != nil {
+		t.Fatal(err)
+	}
+	if service.block != "0x10" {
+		t.Fatalf("expected block 0x10, got %s", service.block)
+	}
+}
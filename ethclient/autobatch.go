@@ -0,0 +1,181 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultAutoBatchMethods are the methods WithAutoBatch batches when no methods are given.
+var DefaultAutoBatchMethods = []string{
+	"eth_getBalance",
+	"eth_getCode",
+	"eth_getStorageAt",
+	"eth_getTransactionCount",
+}
+
+// DefaultAutoBatchTimeout bounds a batch sent for callers without a deadline.
+const DefaultAutoBatchTimeout = 30 * time.Second
+
+// WithAutoBatch collects concurrent calls of methods (DefaultAutoBatchMethods if empty) for up
+// to window, or until maxSize calls are pending, and sends them as one batch request.
+// Every caller still gets its own result and error, and returns as soon as its own context is done.
+func WithAutoBatch(window time.Duration, maxSize int, methods ...string) Option {
+	return func(cfg *dialConfig) {
+		if len(methods) == 0 {
+			methods = DefaultAutoBatchMethods
+		}
+		b := &autoBatcher{
+			window:  window,
+			maxSize: max(maxSize, 1),
+			methods: make(map[string]bool, len(methods)),
+		}
+		for _, m := range methods {
+			b.methods[m] = true
+		}
+		cfg.autoBatch = b
+	}
+}
+
+type autoBatcher struct {
+	ec      *Client
+	window  time.Duration
+	maxSize int
+	methods map[string]bool
+
+	mu      sync.Mutex
+	pending []*batchedCall
+	timer   *time.Timer
+}
+
+type batchedCall struct {
+	ctx  context.Context
+	elem rpc.BatchElem
+	done chan error
+}
+
+// call queues the request and waits for the batch it ends up in.
+func (b *autoBatcher) call(ctx context.Context, result any, method string, args ...any) error {
+	// the batch decodes into its own buffer so that a caller that gave up never races with it
+	raw := new(json.RawMessage)
+	c := &batchedCall{
+		ctx:  ctx,
+		elem: rpc.BatchElem{Method: method, Args: args, Result: raw},
+		done: make(chan error, 1),
+	}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, c)
+	if len(b.pending) >= b.maxSize {
+		calls := b.take()
+		b.mu.Unlock()
+		go b.send(calls)
+	} else {
+		if len(b.pending) == 1 {
+			b.timer = time.AfterFunc(b.window, b.flush)
+		}
+		b.mu.Unlock()
+	}
+
+	select {
+	case err := <-c.done:
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(*raw, result)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// take removes the pending calls, b.mu must be held.
+func (b *autoBatcher) take() []*batchedCall {
+	calls := b.pending
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return calls
+}
+
+func (b *autoBatcher) flush() {
+	b.mu.Lock()
+	calls := b.take()
+	b.mu.Unlock()
+	if len(calls) > 0 {
+		b.send(calls)
+	}
+}
+
+func (b *autoBatcher) send(calls []*batchedCall) {
+	// callers that already gave up don't need to be sent
+	live := calls[:0]
+	for _, c := range calls {
+		if c.ctx.Err() == nil {
+			live = append(live, c)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+	ctx, cancel := batchContext(live)
+	defer cancel()
+	if len(live) == 1 {
+		c := live[0]
+		c.done <- b.ec.call(ctx, c.elem.Result, c.elem.Method, c.elem.Args...)
+		return
+	}
+
+	elems := make([]rpc.BatchElem, len(live))
+	for i, c := range live {
+		elems[i] = c.elem
+	}
+	err := b.ec.batchCallContext(ctx, elems)
+	for i, c := range live {
+		if err != nil {
+			c.done <- err
+		} else {
+			c.done <- elems[i].Error
+		}
+	}
+}
+
+// batchContext returns the context a batch is sent with. It keeps the values of the first
+// caller, ends at the latest deadline of the callers (DefaultAutoBatchTimeout for callers
+// without one) and is cancelled once every caller gave up.
+func batchContext(live []*batchedCall) (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	for _, c := range live {
+		d, ok := c.ctx.Deadline()
+		if !ok {
+			d = time.Now().Add(DefaultAutoBatchTimeout)
+		}
+		if d.After(deadline) {
+			deadline = d
+		}
+	}
+	ctx, cancel := context.WithDeadline(context.WithoutCancel(live[0].ctx), deadline)
+
+	var mu sync.Mutex
+	waiting := len(live)
+	stops := make([]func() bool, len(live))
+	for i, c := range live {
+		stops[i] = context.AfterFunc(c.ctx, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if waiting--; waiting == 0 {
+				cancel()
+			}
+		})
+	}
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel()
+	}
+}
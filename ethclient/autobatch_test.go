@@ -0,0 +1,141 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/hexutil"
+)
+
+type autoBatchService struct{}
+
+func (autoBatchService) GetBalance(account ecommon.Address, block string) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).SetBytes(account[18:]))
+}
+
+func (autoBatchService) GetCode(account ecommon.Address, block string) (hexutil.Bytes, error) {
+	if account == (ecommon.Address{}) {
+		return nil, errors.New("no code for the zero address")
+	}
+	return hexutil.Bytes{0x60, 0x80}, nil
+}
+
+func newAutoBatchClient(t *testing.T, opt Option) (*Client, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	url := newHTTPTestServer(t, autoBatchService{}, func(http.ResponseWriter, *http.Request) bool {
+		requests.Add(1)
+		return false
+	})
+	ec, err := DialContext(t.Context(), url, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ec.Close)
+	return ec, &requests
+}
+
+func TestAutoBatch(t *testing.T) {
+	ec, requests := newAutoBatchClient(t, WithAutoBatch(50*time.Millisecond, 100))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	balances := make([]*big.Int, 10)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balances[i], errs[i] = ec.BalanceAt(t.Context(), ecommon.BigToAddress(big.NewInt(int64(i))), nil)
+		}()
+	}
+	var codeErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, codeErr = ec.CodeAt(t.Context(), ecommon.Address{}, nil)
+	}()
+	wg.Wait()
+
+	if requests.Load() != 1 {
+		t.Fatalf("expected 1 batch request, got %d", requests.Load())
+	}
+	for i := range 10 {
+		if errs[i] != nil || balances[i].Int64() != int64(i) {
+			t.Fatalf("call %d: got %v (%v)", i, balances[i], errs[i])
+		}
+	}
+	if codeErr == nil {
+		t.Fatal("expected the error of the failing element")
+	}
+}
+
+func TestAutoBatch_MaxSize(t *testing.T) {
+	ec, requests := newAutoBatchClient(t, WithAutoBatch(time.Hour, 4))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ec.BalanceAt(t.Context(), ecommon.BigToAddress(big.NewInt(int64(i))), nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if requests.Load() != 2 {
+		t.Fatalf("expected 2 batch requests, got %d", requests.Load())
+	}
+}
+
+func TestAutoBatch_Single(t *testing.T) {
+	ec, requests := newAutoBatchClient(t, WithAutoBatch(time.Millisecond, 10))
+	code, err := ec.CodeAt(t.Context(), ecommon.Address{1}, nil)
+	if err != nil || len(code) != 2 || requests.Load() != 1 {
+		t.Fatalf("unexpected code %x (%v) after %d requests", code, err, requests.Load())
+	}
+}
+
+func TestAutoBatch_CallersGiveUp(t *testing.T) {
+	abandoned := make(chan struct{})
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// never answers, the batch request must be cancelled once every caller gave up
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		close(abandoned)
+	}))
+	t.Cleanup(httpServer.Close)
+	ec, err := DialContext(t.Context(), httpServer.URL, WithAutoBatch(time.Millisecond, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ec.Close)
+
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+			if _, err := ec.BalanceAt(ctx, ecommon.BigToAddress(big.NewInt(int64(i))), nil); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected context.DeadlineExceeded, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case <-abandoned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the batch request was not cancelled")
+	}
+}
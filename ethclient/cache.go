@@ -0,0 +1,269 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/donutnomad/eths/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+// Cache stores raw JSON-RPC results by request key. Implementations must be safe for concurrent use,
+// e.g. an adapter over redis or memcached.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entries.
+type LRUCache struct {
+	c *lru.Cache[string, []byte]
+}
+
+// NewLRUCache returns an in-memory cache holding up to size entries.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{c: lru.NewCache[string, []byte](size)}
+}
+
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	return c.c.Get(key)
+}
+
+func (c *LRUCache) Set(key string, value []byte) {
+	c.c.Add(key, value)
+}
+
+// Len returns the number of cached entries.
+func (c *LRUCache) Len() int {
+	return c.c.Len()
+}
+
+// CachePolicy decides when a result is old enough to be cached.
+type CachePolicy struct {
+	// Confirmations is the depth below the head a block must have before results bound to it are cached.
+	Confirmations uint64
+	// Tag is "safe" or "finalized". If set, results are cached once their block is at or below the
+	// tagged block and Confirmations is ignored.
+	Tag string
+	// HeadTTL is how long the head (or tagged) block number is reused, the default is 2s.
+	HeadTTL time.Duration
+}
+
+// WithCache caches the results that can't change anymore:
+//
+//   - everything addressed by block hash (blocks, receipts, state and calls at a block hash)
+//   - blocks, receipts, state and calls at a block number that is final according to policy
+//   - transactions and receipts looked up by hash once their block is final according to policy
+//   - eth_chainId
+//
+// Results of block tags such as latest or pending and empty (null) results are never cached. Keys
+// start with the chain ID of the client, so one cache can be shared by clients of different chains.
+func WithCache(cache Cache, policy CachePolicy) Option {
+	return func(cfg *dialConfig) {
+		if policy.HeadTTL <= 0 {
+			policy.HeadTTL = 2 * time.Second
+		}
+		cfg.cache = &responseCache{cache: cache, policy: policy}
+	}
+}
+
+type responseCache struct {
+	ec     *Client
+	cache  Cache
+	policy CachePolicy
+
+	mu        sync.Mutex
+	chainID   string
+	final     uint64
+	fetchedAt time.Time
+}
+
+// cacheBinding says what a result is bound to.
+type cacheBinding int
+
+const (
+	bindNone        cacheBinding = iota // never cached
+	bindImmutable                       // always cached
+	bindArgBlock                        // cached when the block argument is final
+	bindResultBlock                     // cached when the blockNumber of the result is final
+)
+
+// cacheMethods maps the cacheable methods to their binding and the index of their block argument.
+var cacheMethods = map[string]struct {
+	binding cacheBinding
+	arg     int
+}{
+	"eth_chainId":                             {bindImmutable, -1},
+	"eth_getBlockByHash":                      {bindImmutable, -1},
+	"eth_getTransactionByBlockHashAndIndex":   {bindImmutable, -1},
+	"eth_getBlockByNumber":                    {bindArgBlock, 0},
+	"eth_getBlockReceipts":                    {bindArgBlock, 0},
+	"eth_getTransactionByBlockNumberAndIndex": {bindArgBlock, 0},
+	"eth_getBalance":                          {bindArgBlock, 1},
+	"eth_getCode":                             {bindArgBlock, 1},
+	"eth_getTransactionCount":                 {bindArgBlock, 1},
+	"eth_call":                                {bindArgBlock, 1},
+	"eth_getStorageAt":                        {bindArgBlock, 2},
+	"eth_getTransactionByHash":                {bindResultBlock, -1},
+	"eth_getTransactionReceipt":               {bindResultBlock, -1},
+}
+
+func (rc *responseCache) call(ctx context.Context, result any, method string, args ...any) error {
+	m, ok := cacheMethods[method]
+	if !ok || result == nil {
+		return rc.ec.uncachedCall(ctx, result, method, args...)
+	}
+	encodedArgs, err := json.Marshal(args)
+	if err != nil {
+		return rc.ec.uncachedCall(ctx, result, method, args...)
+	}
+	binding := m.binding
+	var argBlock uint64
+	if binding == bindArgBlock {
+		var rawArgs []json.RawMessage
+		_ = json.Unmarshal(encodedArgs, &rawArgs)
+		if m.arg >= len(rawArgs) {
+			binding = bindNone
+		} else {
+			binding, argBlock = blockSelectorBinding(rawArgs[m.arg])
+		}
+	}
+	if binding == bindNone {
+		return rc.ec.uncachedCall(ctx, result, method, args...)
+	}
+
+	chainID, ok := rc.chainIDKey(ctx)
+	if !ok {
+		return rc.ec.uncachedCall(ctx, result, method, args...)
+	}
+	key := chainID + ":" + method + ":" + string(encodedArgs)
+	if cached, ok := rc.cache.Get(key); ok {
+		return json.Unmarshal(cached, result)
+	}
+	var raw json.RawMessage
+	if err := rc.ec.uncachedCall(ctx, &raw, method, args...); err != nil {
+		return err
+	}
+	if rc.cacheable(ctx, binding, argBlock, raw) {
+		rc.cache.Set(key, raw)
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func (rc *responseCache) cacheable(ctx context.Context, binding cacheBinding, argBlock uint64, raw json.RawMessage) bool {
+	if len(raw) == 0 || string(raw) == "null" {
+		return false
+	}
+	switch binding {
+	case bindImmutable:
+		return true
+	case bindArgBlock:
+		return rc.isFinal(ctx, argBlock)
+	case bindResultBlock:
+		var ref struct {
+			BlockNumber *hexutil.Big `json:"blockNumber"`
+		}
+		if err := json.Unmarshal(raw, &ref); err != nil || ref.BlockNumber == nil || !ref.BlockNumber.ToInt().IsUint64() {
+			return false
+		}
+		return rc.isFinal(ctx, ref.BlockNumber.ToInt().Uint64())
+	default:
+		return false
+	}
+}
+
+// chainIDKey returns the chain ID of the client, it's fetched once and kept for the client lifetime.
+func (rc *responseCache) chainIDKey(ctx context.Context) (string, bool) {
+	rc.mu.Lock()
+	chainID := rc.chainID
+	rc.mu.Unlock()
+	if chainID != "" {
+		return chainID, true
+	}
+
+	var id hexutil.Big
+	if err := rc.ec.uncachedCall(ctx, &id, "eth_chainId"); err != nil {
+		return "", false
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.chainID = id.String()
+	return rc.chainID, true
+}
+
+// isFinal reports whether block can't be reorged anymore according to the policy.
+func (rc *responseCache) isFinal(ctx context.Context, block uint64) bool {
+	final, ok := rc.finalBlock(ctx)
+	return ok && block <= final
+}
+
+func (rc *responseCache) finalBlock(ctx context.Context) (uint64, bool) {
+	rc.mu.Lock()
+	if !rc.fetchedAt.IsZero() && time.Since(rc.fetchedAt) < rc.policy.HeadTTL {
+		defer rc.mu.Unlock()
+		return rc.final, true
+	}
+	rc.mu.Unlock()
+
+	var final uint64
+	if rc.policy.Tag != "" {
+		var head struct {
+			Number *hexutil.Big `json:"number"`
+		}
+		if err := rc.ec.uncachedCall(ctx, &head, "eth_getBlockByNumber", rc.policy.Tag, false); err != nil || head.Number == nil {
+			return 0, false
+		}
+		final = head.Number.ToInt().Uint64()
+	} else {
+		var head hexutil.Uint64
+		if err := rc.ec.uncachedCall(ctx, &head, "eth_blockNumber"); err != nil {
+			return 0, false
+		}
+		if uint64(head) < rc.policy.Confirmations {
+			return 0, false
+		}
+		final = uint64(head) - rc.policy.Confirmations
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.final, rc.fetchedAt = final, time.Now()
+	return final, true
+}
+
+// blockSelectorBinding classifies an encoded block argument: a block hash is immutable, a block
+// number is bound to that block and a tag (latest, pending, ...) is never cached.
+func blockSelectorBinding(arg json.RawMessage) (cacheBinding, uint64) {
+	var s string
+	if err := json.Unmarshal(arg, &s); err == nil {
+		return stringSelectorBinding(s)
+	}
+	var obj struct {
+		BlockHash   *string `json:"blockHash"`
+		BlockNumber *string `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(arg, &obj); err != nil {
+		return bindNone, 0
+	}
+	if obj.BlockHash != nil {
+		return bindImmutable, 0
+	}
+	if obj.BlockNumber != nil {
+		return stringSelectorBinding(*obj.BlockNumber)
+	}
+	return bindNone, 0
+}
+
+func stringSelectorBinding(s string) (cacheBinding, uint64) {
+	if len(s) == 66 && strings.HasPrefix(s, "0x") {
+		return bindImmutable, 0
+	}
+	n, err := hexutil.DecodeUint64(s)
+	if err != nil {
+		return bindNone, 0
+	}
+	return bindArgBlock, n
+}
@@ -0,0 +1,141 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/hexutil"
+)
+
+type cacheService struct {
+	chainID      uint64
+	codeCalls    atomic.Int64
+	receiptCalls atomic.Int64
+}
+
+func (s *cacheService) ChainId() hexutil.Uint64 {
+	return hexutil.Uint64(s.chainID)
+}
+
+func (s *cacheService) BlockNumber() hexutil.Uint64 {
+	return 100
+}
+
+func (s *cacheService) GetBlockByNumber(tag string, full bool) map[string]any {
+	if tag == "finalized" {
+		return map[string]any{"number": "0x50"}
+	}
+	return nil
+}
+
+func (s *cacheService) GetCode(account ecommon.Address, block json.RawMessage) hexutil.Bytes {
+	s.codeCalls.Add(1)
+	return hexutil.Bytes{0x60}
+}
+
+func (s *cacheService) GetTransactionReceipt(hash ecommon.Hash) map[string]any {
+	s.receiptCalls.Add(1)
+	if hash == (ecommon.Hash{}) {
+		return nil
+	}
+	return map[string]any{"transactionHash": hash, "blockNumber": hexutil.EncodeBig(hash.Big())}
+}
+
+func newCacheTestClient(t *testing.T, policy CachePolicy) (*Client, *cacheService, *LRUCache) {
+	t.Helper()
+	service := &cacheService{chainID: 1}
+	cache := NewLRUCache(100)
+	ec := newInProcClient(t, service, WithCache(cache, policy))
+	return ec, service, cache
+}
+
+func TestCache_Confirmations(t *testing.T) {
+	ec, service, cache := newCacheTestClient(t, CachePolicy{Confirmations: 10})
+	ctx := t.Context()
+	account := ecommon.Address{1}
+
+	for _, tt := range []struct {
+		name   string
+		call   func() error
+		cached bool
+	}{
+		{"at hash", func() error { _, err := ec.CodeAtHash(ctx, account, ecommon.Hash{1}); return err }, true},
+		{"final block", func() error { _, err := ec.CodeAt(ctx, account, big.NewInt(90)); return err }, true},
+		{"unconfirmed block", func() error { _, err := ec.CodeAt(ctx, account, big.NewInt(91)); return err }, false},
+		{"latest", func() error { _, err := ec.CodeAt(ctx, account, nil); return err }, false},
+	} {
+		before := service.codeCalls.Load()
+		for range 2 {
+			if err := tt.call(); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+		want := int64(2)
+		if tt.cached {
+			want = 1
+		}
+		if got := service.codeCalls.Load() - before; got != want {
+			t.Fatalf("%s: expected %d requests, got %d", tt.name, want, got)
+		}
+	}
+
+	for _, tt := range []struct {
+		block  int64
+		cached bool
+	}{{90, true}, {95, false}, {0, false}} {
+		before := service.receiptCalls.Load()
+		hash := ecommon.BigToHash(big.NewInt(tt.block))
+		for range 2 {
+			_, _ = TransactionReceiptAs[json.RawMessage](ctx, ec, hash)
+		}
+		want := int64(2)
+		if tt.cached {
+			want = 1
+		}
+		if got := service.receiptCalls.Load() - before; got != want {
+			t.Fatalf("receipt in block %d: expected %d requests, got %d", tt.block, want, got)
+		}
+	}
+	if cache.Len() != 3 {
+		t.Fatalf("expected 3 cached entries, got %d", cache.Len())
+	}
+}
+
+func TestCache_FinalizedTag(t *testing.T) {
+	ec, service, _ := newCacheTestClient(t, CachePolicy{Tag: "finalized"})
+	ctx := t.Context()
+	for _, block := range []int64{0x50, 0x51} {
+		before := service.codeCalls.Load()
+		for range 2 {
+			if _, err := ec.CodeAt(ctx, ecommon.Address{1}, big.NewInt(block)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cached := service.codeCalls.Load()-before == 1
+		if cached != (block == 0x50) {
+			t.Fatalf("block %d: cached=%v", block, cached)
+		}
+	}
+}
+
+func TestCache_SharedAcrossChains(t *testing.T) {
+	cache := NewLRUCache(100)
+	policy := CachePolicy{Confirmations: 10}
+	mainnet := &cacheService{chainID: 1}
+	sepolia := &cacheService{chainID: 11155111}
+	for _, service := range []*cacheService{mainnet, sepolia} {
+		ec := newInProcClient(t, service, WithCache(cache, policy))
+		if _, err := ec.CodeAtHash(t.Context(), ecommon.Address{1}, ecommon.Hash{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mainnet.codeCalls.Load() != 1 || sepolia.codeCalls.Load() != 1 {
+		t.Fatalf("expected one request per chain, got %d and %d", mainnet.codeCalls.Load(), sepolia.codeCalls.Load())
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 cached entries, got %d", cache.Len())
+	}
+}
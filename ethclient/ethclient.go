@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/donutnomad/eths/ecommon"
@@ -37,17 +38,35 @@ import (
 
 // Client defines typed wrappers for the Ethereum RPC API.
 type Client struct {
-	c  *rpc.Client
-	rt *headerCapture
-	od *overloadDetector
+	c    *rpc.Client
+	rt   *headerCapture
+	od   *overloadDetector
+	pool *Pool
+
+	retryPolicy *RetryPolicy
+	limiter     *RateLimiter
+	autoBatch   *autoBatcher
+	cache       *responseCache
+	observers   []Observer
+
+	pollInterval time.Duration
+	// noBlockReceipts is set once the endpoint rejected eth_getBlockReceipts as unsupported
+	noBlockReceipts atomic.Bool
 }
 
-// Option configures the Client created by DialContext.
+// Option configures the Client created by DialContext or NewClient.
 type Option func(*dialConfig)
 
 type dialConfig struct {
 	httpClient *http.Client
 	rpcOpts    []rpc.ClientOption
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	autoBatch  *autoBatcher
+	cache      *responseCache
+	observers  []Observer
+
+	pollInterval time.Duration
 }
 
 // WithHTTPClient configures the base http.Client used by the RPC client.
@@ -96,37 +115,131 @@ func DialContext(ctx context.Context, rawurl string, opts ...Option) (*Client, e
 	if err != nil {
 		return nil, err
 	}
-	return &Client{c: c, rt: hc, od: newOverloadDetector(50, 0.5)}, nil
+	return newClient(c, hc, cfg), nil
 }
 
 // NewClient creates a client that uses the given RPC client.
-func NewClient(c *rpc.Client) *Client {
-	return &Client{c: c, od: newOverloadDetector(50, 0.5)}
+// Options that configure the transport (WithHTTPClient, WithRPCOptions) have no effect.
+func NewClient(c *rpc.Client, opts ...Option) *Client {
+	var cfg dialConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return newClient(c, nil, cfg)
+}
+
+func newClient(c *rpc.Client, hc *headerCapture, cfg dialConfig) *Client {
+	ec := &Client{c: c, rt: hc, od: newOverloadDetector(50, 0.5), retryPolicy: cfg.retry, limiter: cfg.limiter, observers: cfg.observers, pollInterval: cfg.pollInterval}
+	if cfg.autoBatch != nil {
+		ec.autoBatch = cfg.autoBatch
+		ec.autoBatch.ec = ec
+	}
+	if cfg.cache != nil {
+		ec.cache = cfg.cache
+		ec.cache.ec = ec
+	}
+	return ec
 }
 
 // Close closes the underlying RPC connection.
 func (ec *Client) Close() {
+	if ec.pool != nil {
+		ec.pool.close()
+		return
+	}
 	ec.c.Close()
 }
 
 // Client gets the underlying RPC client.
+// For a pool client it is the client of the currently preferred endpoint.
 func (ec *Client) Client() *rpc.Client {
+	if ec.pool != nil {
+		return ec.pool.rpcClient()
+	}
 	return ec.c
 }
 
+// Pool returns the endpoint pool of a client created by NewPoolClient or DialPool, nil otherwise.
+func (ec *Client) Pool() *Pool {
+	return ec.pool
+}
+
 // callContext wraps rpc.Client.CallContext and enriches HTTP errors with
 // captured response headers when the client was created via DialContext.
 func (ec *Client) callContext(ctx context.Context, result any, method string, args ...any) error {
-	err := wrapErr(ec.rt, ec.c.CallContext(ctx, result, method, args...))
-	ec.od.record(IsRateLimited(err))
-	return err
+	if ec.cache != nil {
+		return ec.cache.call(ctx, result, method, args...)
+	}
+	return ec.uncachedCall(ctx, result, method, args...)
+}
+
+// uncachedCall sends the request, batched with concurrent ones when auto batching is enabled.
+func (ec *Client) uncachedCall(ctx context.Context, result any, method string, args ...any) error {
+	if ec.autoBatch != nil && result != nil && ec.autoBatch.methods[method] {
+		return ec.autoBatch.call(ctx, result, method, args...)
+	}
+	return ec.call(ctx, result, method, args...)
+}
+
+// call sends a single request, see callContext.
+func (ec *Client) call(ctx context.Context, result any, method string, args ...any) error {
+	return ec.retry(ctx, ec.retryPolicy.retries(method), func(attempt int) error {
+		if err := ec.limiter.waitMethod(ctx, method); err != nil {
+			return err
+		}
+		var err error
+		if len(ec.observers) > 0 {
+			err = ec.observeCall(ctx, attempt, result, method, args...)
+		} else {
+			err = ec.send(ctx, result, method, args...)
+		}
+		ec.od.record(IsRateLimited(err))
+		return err
+	})
+}
+
+func (ec *Client) send(ctx context.Context, result any, method string, args ...any) error {
+	if ec.pool != nil {
+		return ec.pool.callContext(ctx, result, method, args...)
+	}
+	return wrapErr(ec.rt, ec.c.CallContext(ctx, result, method, args...))
 }
 
 // batchCallContext wraps rpc.Client.BatchCallContext and enriches HTTP errors.
+// A batch is retried as a whole with the smallest retry limit of its methods.
 func (ec *Client) batchCallContext(ctx context.Context, b []rpc.BatchElem) error {
-	err := wrapErr(ec.rt, ec.c.BatchCallContext(ctx, b))
-	ec.od.record(IsRateLimited(err))
-	return err
+	retries := ec.retryPolicy.retries("")
+	for _, elem := range b {
+		retries = min(retries, ec.retryPolicy.retries(elem.Method))
+	}
+	return ec.retry(ctx, retries, func(attempt int) error {
+		if err := ec.limiter.waitBatch(ctx, b); err != nil {
+			return err
+		}
+		var err error
+		if len(ec.observers) > 0 {
+			err = ec.observeBatch(ctx, attempt, b)
+		} else {
+			err = ec.sendBatch(ctx, b)
+		}
+		ec.od.record(IsRateLimited(err))
+		return err
+	})
+}
+
+func (ec *Client) sendBatch(ctx context.Context, b []rpc.BatchElem) error {
+	if ec.pool != nil {
+		return ec.pool.batchCallContext(ctx, b)
+	}
+	return wrapErr(ec.rt, ec.c.BatchCallContext(ctx, b))
+}
+
+// ethSubscribe wraps rpc.Client.EthSubscribe.
+func (ec *Client) ethSubscribe(ctx context.Context, channel any, args ...any) (*rpc.ClientSubscription, error) {
+	if ec.pool != nil {
+		return ec.pool.ethSubscribe(ctx, channel, args...)
+	}
+	return ec.c.EthSubscribe(ctx, channel, args...)
 }
 
 // IsOverloaded reports whether the client is currently overloaded.
@@ -256,8 +369,12 @@ func (ec *Client) PeerCount(ctx context.Context) (uint64, error) {
 // BlockReceipts returns the receipts of a given block number or hash.
 //
 // RPC: https://ethereum.org/en/developers/docs/apis/json-rpc/#eth_getblockreceipts
+//
+// If the endpoint doesn't support eth_getBlockReceipts, the receipts of the block's transactions
+// are fetched in batches of ReceiptsBatchSize and checked against the block, see ErrReceiptsMismatch.
+// An endpoint that answered with "method not found" (-32601) goes straight to the fallback afterwards.
 func (ec *Client) BlockReceipts(ctx context.Context, blockNrOrHash ethtype.BlockNumberOrHash) ([]*ethtype.TxReceipt, error) {
-	return CallNotFound[[]*ethtype.TxReceipt](ec, ctx, "eth_getBlockReceipts", blockNrOrHash.String())
+	return BlockReceiptsAs[*ethtype.TxReceipt](ctx, ec, blockNrOrHash)
 }
 
 // BlockReceiptsAs is the generic version of BlockReceipts. It allows the
@@ -270,8 +387,23 @@ func (ec *Client) BlockReceipts(ctx context.Context, blockNrOrHash ethtype.Block
 //	    Status uint64      `json:"status"`
 //	}
 //	receipts, err := ethclient.BlockReceiptsAs[*LiteReceipt](ctx, client, blockNrOrHash)
+//
+// The fallback for endpoints without eth_getBlockReceipts only checks the number of receipts
+// unless T is *ethtype.TxReceipt. A pool client sends all requests of the call to one endpoint,
+// and each endpoint remembers on its own whether it lacks eth_getBlockReceipts.
 func BlockReceiptsAs[T any](ctx context.Context, ec *Client, blockNrOrHash ethtype.BlockNumberOrHash) ([]T, error) {
-	return CallNotFound[[]T](ec, ctx, "eth_getBlockReceipts", blockNrOrHash.String())
+	ec = ec.pinned()
+	if !ec.noBlockReceipts.Load() {
+		receipts, err := CallNotFound[[]T](ec, ctx, "eth_getBlockReceipts", blockNrOrHash.String())
+		unsupported, sure := methodUnsupported(err, "eth_getBlockReceipts")
+		if !unsupported {
+			return receipts, err
+		}
+		if sure {
+			ec.noBlockReceipts.Store(true)
+		}
+	}
+	return blockReceiptsByTx[T](ctx, ec, blockNrOrHash)
 }
 
 // HeaderByHash returns the block header with the given hash.
@@ -384,7 +516,11 @@ func TransactionReceiptAs[T any](ctx context.Context, ec *Client, txHash ecommon
 
 // SubscribeTransactionReceipts subscribes to notifications about transaction receipts.
 func (ec *Client) SubscribeTransactionReceipts(ctx context.Context, q *ethereum.TransactionReceiptsQuery, ch chan<- []*ethtype.Receipt) (ethereum.Subscription, error) {
-	return ec.c.EthSubscribe(ctx, ch, "transactionReceipts", q)
+	sub, err := ec.ethSubscribe(ctx, ch, "transactionReceipts", q)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
 }
 
 // SyncProgress retrieves the current progress of the sync algorithm. If there's
@@ -411,7 +547,10 @@ func (ec *Client) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, err
 // SubscribeNewHead subscribes to notifications about the current blockchain head
 // on the given channel.
 func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *ethtype.Header) (ethereum.Subscription, error) {
-	sub, err := ec.c.EthSubscribe(ctx, ch, "newHeads")
+	sub, err := ec.ethSubscribe(ctx, ch, "newHeads")
+	if ec.canPoll(err) {
+		return ec.PollNewHead(ctx, ch, ec.pollInterval)
+	}
 	if err != nil {
 		// Defensively prefer returning nil interface explicitly on error-path, instead
 		// of letting default golang behavior wrap it with non-nil interface that stores
@@ -532,7 +671,10 @@ func (ec *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuer
 	if err != nil {
 		return nil, err
 	}
-	sub, err := ec.c.EthSubscribe(ctx, ch, "logs", arg)
+	sub, err := ec.ethSubscribe(ctx, ch, "logs", arg)
+	if ec.canPoll(err) {
+		return ec.PollFilterLogs(ctx, q, ch, ec.pollInterval)
+	}
 	if err != nil {
 		// Defensively prefer returning nil interface explicitly on error-path, instead
 		// of letting default golang behavior wrap it with non-nil interface that stores
@@ -727,6 +869,40 @@ func (ec *Client) EstimateGasAtBlockHash(ctx context.Context, msg ethereum.CallM
 	return uint64(hex), err
 }
 
+// AccessListResult is the result of eth_createAccessList.
+type AccessListResult struct {
+	AccessList ethtype.AccessList
+	// GasUsed is the gas used by the call with the access list applied.
+	GasUsed uint64
+	// Error is set when the call reverts, the access list is still returned.
+	Error string
+}
+
+// CreateAccessList creates an EIP-2930 access list for msg, executed on top of the given block
+// (nil means pending).
+//
+// RPC: https://ethereum.github.io/execution-apis/api-documentation/ (eth_createAccessList)
+func (ec *Client) CreateAccessList(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (*AccessListResult, error) {
+	type accessListResult struct {
+		AccessList ethtype.AccessList `json:"accessList"`
+		GasUsed    hexutil.Uint64     `json:"gasUsed"`
+		Error      string             `json:"error,omitempty"`
+	}
+	blockArg := "pending"
+	if blockNumber != nil {
+		blockArg = toBlockNumArg(blockNumber)
+	}
+	res, err := Call[accessListResult](ec, ctx, "eth_createAccessList", toCallArg(msg), blockArg)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessListResult{
+		AccessList: res.AccessList,
+		GasUsed:    uint64(res.GasUsed),
+		Error:      res.Error,
+	}, nil
+}
+
 // SendTransaction injects a signed transaction into the pending pool for execution.
 //
 // If the transaction was a contract creation use the TransactionReceipt method to get the
@@ -0,0 +1,255 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/ethereum/go-ethereum"
+)
+
+// ErrReorgTooDeep is returned by BlockFollower.Next with the rollback of the whole window when a
+// reorg reaches below its oldest block. Following continues from the oldest orphaned block.
+var ErrReorgTooDeep = errors.New("reorg deeper than the block window")
+
+// BlockRef identifies a block.
+type BlockRef struct {
+	Number uint64       `json:"number"`
+	Hash   ecommon.Hash `json:"hash"`
+}
+
+// Checkpoint is the state of a BlockFollower, persist it to resume after a restart.
+type Checkpoint struct {
+	// Blocks are the most recent delivered blocks, oldest first
+	Blocks []BlockRef `json:"blocks"`
+}
+
+// Head returns the last delivered block.
+func (c *Checkpoint) Head() (BlockRef, bool) {
+	if c == nil || len(c.Blocks) == 0 {
+		return BlockRef{}, false
+	}
+	return c.Blocks[len(c.Blocks)-1], true
+}
+
+// FollowerConfig configures a BlockFollower.
+type FollowerConfig struct {
+	// Confirmations is the depth below the head a block must have to be delivered.
+	Confirmations uint64
+	// Window is the number of recent blocks remembered to detect reorgs, the default is 128.
+	Window int
+	// PollInterval is how often the head is polled once caught up, the default is DefaultPollInterval.
+	PollInterval time.Duration
+	// Checkpoint resumes after the head of a previous run. If nil, following starts at From,
+	// or at the current confirmed head if From is nil too.
+	Checkpoint *Checkpoint
+	From       *big.Int
+}
+
+// FollowEventType is the type of a FollowEvent.
+type FollowEventType int
+
+const (
+	// FollowBlock delivers the next canonical block.
+	FollowBlock FollowEventType = iota
+	// FollowRollback reports delivered blocks that are no longer canonical.
+	FollowRollback
+)
+
+// FollowEvent is a block or a rollback, see BlockFollower.Next.
+type FollowEvent[B any] struct {
+	Type  FollowEventType
+	Block *B
+	// Orphaned are the blocks removed by a rollback, newest first. Blocks delivered before a
+	// restart are fetched by hash, if the node doesn't have them anymore only their number and
+	// hash are set.
+	Orphaned []*B
+}
+
+// BlockFollower delivers canonical blocks in order and reports reorgs as rollbacks. Every block
+// is checked against the parent hash of the previously delivered one.
+//
+//	f := NewBlockFollower[ethtype.LiteBlock](ec, FollowerConfig{Confirmations: 2, Checkpoint: saved})
+//	for {
+//		ev, err := f.Next(ctx)
+//		...
+//		save(f.Checkpoint())
+//	}
+type BlockFollower[B ethtype.Block | ethtype.LiteBlock] struct {
+	ec  *Client
+	cfg FollowerConfig
+
+	refs   []BlockRef
+	blocks []*B // blocks[i] is refs[i], nil if restored from a checkpoint
+	next   *big.Int
+	head   uint64
+}
+
+// NewBlockFollower returns a follower of full (ethtype.Block) or lite (ethtype.LiteBlock) blocks.
+func NewBlockFollower[B ethtype.Block | ethtype.LiteBlock](ec *Client, cfg FollowerConfig) *BlockFollower[B] {
+	if cfg.Window <= 0 {
+		cfg.Window = 128
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	f := &BlockFollower[B]{ec: ec, cfg: cfg}
+	if cfg.Checkpoint != nil {
+		f.refs = append(f.refs, cfg.Checkpoint.Blocks...)
+		f.blocks = make([]*B, len(f.refs))
+	}
+	if head, ok := cfg.Checkpoint.Head(); ok {
+		f.next = new(big.Int).SetUint64(head.Number + 1)
+	} else if cfg.From != nil {
+		f.next = new(big.Int).Set(cfg.From)
+	}
+	return f
+}
+
+// Checkpoint returns the state after the last event.
+func (f *BlockFollower[B]) Checkpoint() *Checkpoint {
+	return &Checkpoint{Blocks: append([]BlockRef(nil), f.refs...)}
+}
+
+// Next blocks until the next block is confirmed, or returns a rollback if the chain reorganized.
+// After a rollback the blocks of the new chain follow. Once ctx is done its error is returned.
+func (f *BlockFollower[B]) Next(ctx context.Context) (*FollowEvent[B], error) {
+	event, err := f.follow(ctx)
+	if err != nil && ctx.Err() != nil {
+		// a request interrupted by ctx fails with a transport error that hides the cause
+		err = ctx.Err()
+	}
+	return event, err
+}
+
+func (f *BlockFollower[B]) follow(ctx context.Context) (*FollowEvent[B], error) {
+	for {
+		if err := f.waitConfirmed(ctx); err != nil {
+			return nil, err
+		}
+		block, err := f.blockByNumber(ctx, f.next)
+		if errors.Is(err, ethereum.NotFound) {
+			// the node lags behind the head it reported
+			select {
+			case <-time.After(f.cfg.PollInterval):
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		header := blockHeader(block)
+		if len(f.refs) == 0 || header.ParentHash == f.refs[len(f.refs)-1].Hash {
+			f.push(BlockRef{Number: header.Number.Uint64(), Hash: header.Hash}, block)
+			return &FollowEvent[B]{Type: FollowBlock, Block: block}, nil
+		}
+		orphaned, err := f.rollback(ctx)
+		if len(orphaned) > 0 {
+			return &FollowEvent[B]{Type: FollowRollback, Orphaned: orphaned}, err
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitConfirmed waits until f.next has enough confirmations.
+func (f *BlockFollower[B]) waitConfirmed(ctx context.Context) error {
+	for {
+		if f.next != nil && f.head >= f.next.Uint64()+f.cfg.Confirmations {
+			return nil
+		}
+		head, err := f.ec.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		if f.next == nil {
+			f.next = new(big.Int).SetUint64(head - min(head, f.cfg.Confirmations))
+		}
+		if f.head = head; head >= f.next.Uint64()+f.cfg.Confirmations {
+			return nil
+		}
+		select {
+		case <-time.After(f.cfg.PollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// rollback removes the delivered blocks that are no longer canonical, newest first.
+func (f *BlockFollower[B]) rollback(ctx context.Context) ([]*B, error) {
+	var orphaned []*B
+	for len(f.refs) > 0 {
+		last := len(f.refs) - 1
+		ref := f.refs[last]
+		canonical, err := f.ec.HeaderByNumber(ctx, new(big.Int).SetUint64(ref.Number))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		if err == nil && canonical.Hash == ref.Hash {
+			break
+		}
+		block := f.blocks[last]
+		if block == nil {
+			block = f.orphanedBlock(ctx, ref)
+		}
+		orphaned = append(orphaned, block)
+		f.refs, f.blocks = f.refs[:last], f.blocks[:last]
+		f.next = new(big.Int).SetUint64(ref.Number)
+	}
+	if len(f.refs) == 0 {
+		return orphaned, fmt.Errorf("%w: %d blocks", ErrReorgTooDeep, f.cfg.Window)
+	}
+	return orphaned, nil
+}
+
+// orphanedBlock fetches a block of the checkpoint, or returns a stub with its number and hash.
+func (f *BlockFollower[B]) orphanedBlock(ctx context.Context, ref BlockRef) *B {
+	var block *B
+	var err error
+	if _, ok := any(block).(*ethtype.Block); ok {
+		block, err = BlockByHashAs[B](ctx, f.ec, ref.Hash)
+	} else {
+		block, err = LiteBlockByHashAs[B](ctx, f.ec, ref.Hash)
+	}
+	if err != nil {
+		block = new(B)
+		header := blockHeader(block)
+		header.Number, header.Hash = new(big.Int).SetUint64(ref.Number), ref.Hash
+	}
+	return block
+}
+
+func (f *BlockFollower[B]) blockByNumber(ctx context.Context, number *big.Int) (*B, error) {
+	if _, ok := any((*B)(nil)).(*ethtype.Block); ok {
+		return BlockByNumberAs[B](ctx, f.ec, number)
+	}
+	return LiteBlockByNumberAs[B](ctx, f.ec, number)
+}
+
+func (f *BlockFollower[B]) push(ref BlockRef, block *B) {
+	f.refs = append(f.refs, ref)
+	f.blocks = append(f.blocks, block)
+	if extra := len(f.refs) - f.cfg.Window; extra > 0 {
+		f.refs = append(f.refs[:0], f.refs[extra:]...)
+		f.blocks = append(f.blocks[:0], f.blocks[extra:]...)
+	}
+	f.next = new(big.Int).SetUint64(ref.Number + 1)
+}
+
+func blockHeader[B ethtype.Block | ethtype.LiteBlock](block *B) *ethtype.Header {
+	switch b := any(block).(type) {
+	case *ethtype.Block:
+		return &b.Header
+	case *ethtype.LiteBlock:
+		return &b.Header
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,94 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/donutnomad/eths/hexutil"
+)
+
+type followerService struct {
+	*resubService
+}
+
+func (s followerService) BlockNumber() hexutil.Uint64 {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+	return hexutil.Uint64(len(s.chain.hashes) - 1)
+}
+
+func (s followerService) GetBlockByHash(hash ecommon.Hash, full bool) map[string]any {
+	// orphaned blocks are gone
+	return nil
+}
+
+func expectBlock(t *testing.T, f *BlockFollower[ethtype.LiteBlock], number uint64, fork byte) {
+	t.Helper()
+	ev, err := f.Next(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != FollowBlock || ev.Block.Number.Uint64() != number || ev.Block.Hash != (ecommon.Hash{0: byte(number), 1: fork}) {
+		t.Fatalf("expected block %d of fork %d, got %+v", number, fork, ev)
+	}
+}
+
+func expectRollback(t *testing.T, f *BlockFollower[ethtype.LiteBlock], fork byte, numbers ...uint64) {
+	t.Helper()
+	ev, err := f.Next(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != FollowRollback || len(ev.Orphaned) != len(numbers) {
+		t.Fatalf("expected a rollback of %v, got %+v", numbers, ev)
+	}
+	for i, n := range numbers {
+		if b := ev.Orphaned[i]; b.Number.Uint64() != n || b.Hash != (ecommon.Hash{0: byte(n), 1: fork}) {
+			t.Fatalf("orphaned block %d: expected %d of fork %d, got %d %s", i, n, fork, b.Number, b.Hash)
+		}
+	}
+}
+
+func TestBlockFollower(t *testing.T) {
+	chain := newResubChain(10)
+	ec := newInProcClient(t, followerService{&resubService{chain: chain}})
+	cfg := FollowerConfig{Confirmations: 1, From: big.NewInt(8), PollInterval: 10 * time.Millisecond}
+
+	f := NewBlockFollower[ethtype.LiteBlock](ec, cfg)
+	expectBlock(t, f, 8, 0)
+	expectBlock(t, f, 9, 0)
+
+	// block 9 is replaced, the follower rolls back and follows the new chain
+	for n := uint64(9); n <= 11; n++ {
+		chain.setBlock(n, 1, 0)
+	}
+	expectRollback(t, f, 0, 9)
+	expectBlock(t, f, 9, 1)
+	expectBlock(t, f, 10, 1)
+
+	// a new follower resumes from the checkpoint and detects the reorg of its last block
+	checkpoint := f.Checkpoint()
+	if head, _ := checkpoint.Head(); head.Number != 10 || len(checkpoint.Blocks) != 3 {
+		t.Fatalf("unexpected checkpoint %+v", checkpoint)
+	}
+	for n := uint64(10); n <= 12; n++ {
+		chain.setBlock(n, 2, 0)
+	}
+	cfg.Checkpoint = checkpoint
+	f = NewBlockFollower[ethtype.LiteBlock](ec, cfg)
+	expectRollback(t, f, 1, 10)
+	expectBlock(t, f, 10, 2)
+	expectBlock(t, f, 11, 2)
+
+	// the next block isn't confirmed yet
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := f.Next(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected to wait for confirmations, got %v", err)
+	}
+}
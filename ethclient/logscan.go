@@ -0,0 +1,216 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/ethereum/go-ethereum"
+)
+
+// RangeTooLargeMessages are the (lower case) error messages of providers rejecting an
+// eth_getLogs request because its block range or its result is too large.
+var RangeTooLargeMessages = []string{
+	"query returned more than",   // infura, geth
+	"log response size exceeded", // alchemy
+	"block range",                // "block range too large", "block range is too wide", "exceed maximum block range"
+	"range is too large",
+	"is limited to", // quicknode: "eth_getLogs is limited to a 10,000 range"
+	"too many results",
+	"query exceeds",
+	"response size should not greater", // nodereal
+}
+
+// IsRangeTooLarge reports whether err rejects an eth_getLogs request for its size, see RangeTooLargeMessages.
+func IsRangeTooLarge(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range RangeTooLargeMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// LogScanConfig configures a LogScanner.
+type LogScanConfig struct {
+	// ChunkSize is the initial number of blocks per request, the default is 2000.
+	ChunkSize uint64
+	// MaxChunkSize caps the chunk size as it grows after successful requests, the default is 10000.
+	MaxChunkSize uint64
+	// Parallelism is the max number of concurrent requests, the default is 4.
+	Parallelism int
+}
+
+// LogChunk holds the logs of the blocks From..To. To is the checkpoint to persist, a scan
+// resumes with FromBlock To+1.
+type LogChunk struct {
+	From uint64
+	To   uint64
+	Logs []ethtype.Log
+}
+
+// LogScanner fetches the logs of a large block range in chunks. A chunk the provider rejects as
+// too large (IsRangeTooLarge) is split in halves and the chunk size shrinks, after successful
+// requests it grows again. Chunks are fetched in parallel and delivered in order.
+type LogScanner struct {
+	ec  *Client
+	q   ethereum.FilterQuery
+	cfg LogScanConfig
+
+	mu        sync.Mutex
+	chunkSize uint64
+}
+
+// NewLogScanner returns a scanner of the logs matching q from q.FromBlock (0 if nil) to
+// q.ToBlock (the head at the start of the scan if nil). q.BlockHash must be nil.
+func NewLogScanner(ec *Client, q ethereum.FilterQuery, cfg LogScanConfig) *LogScanner {
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = 2000
+	}
+	if cfg.MaxChunkSize == 0 {
+		cfg.MaxChunkSize = 10000
+	}
+	cfg.MaxChunkSize = max(cfg.MaxChunkSize, cfg.ChunkSize)
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = 4
+	}
+	return &LogScanner{ec: ec, q: q, cfg: cfg, chunkSize: cfg.ChunkSize}
+}
+
+// ChunkSize returns the current chunk size.
+func (s *LogScanner) ChunkSize() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chunkSize
+}
+
+// Scan calls fn with the chunks in block order until the range is scanned, fn returns an error
+// or a request fails.
+func (s *LogScanner) Scan(ctx context.Context, fn func(chunk *LogChunk) error) error {
+	if s.q.BlockHash != nil {
+		return errors.New("log scanner doesn't support a block hash")
+	}
+	var from, to uint64
+	if s.q.FromBlock != nil {
+		from = s.q.FromBlock.Uint64()
+	}
+	if s.q.ToBlock != nil {
+		to = s.q.ToBlock.Uint64()
+	} else {
+		head, err := s.ec.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		to = head
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		index int
+		chunk *LogChunk
+		err   error
+	}
+	// buffered for all requests in flight so that no fetch blocks after an early return
+	results := make(chan result, s.cfg.Parallelism)
+	pending := make(map[int]*LogChunk)
+	next, deliver, inflight := 0, 0, 0
+	for cursor, scheduled := from, from > to; ; {
+		for inflight < s.cfg.Parallelism && len(pending) < s.cfg.Parallelism && !scheduled {
+			end := to
+			if size := s.ChunkSize(); to-cursor >= size {
+				end = cursor + size - 1
+			}
+			go func(index int, from, to uint64) {
+				logs, err := s.fetch(ctx, from, to)
+				results <- result{index, &LogChunk{From: from, To: to, Logs: logs}, err}
+			}(next, cursor, end)
+			next, inflight = next+1, inflight+1
+			cursor, scheduled = end+1, end == to
+		}
+		if inflight == 0 {
+			return nil
+		}
+		var r result
+		select {
+		case r = <-results:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		inflight--
+		if r.err != nil {
+			return r.err
+		}
+		pending[r.index] = r.chunk
+		for chunk, ok := pending[deliver]; ok; chunk, ok = pending[deliver] {
+			delete(pending, deliver)
+			deliver++
+			if err := fn(chunk); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Chunks iterates over the chunks of Scan, iteration stops after an error.
+func (s *LogScanner) Chunks(ctx context.Context) iter.Seq2[*LogChunk, error] {
+	errStop := errors.New("stop")
+	return func(yield func(*LogChunk, error) bool) {
+		err := s.Scan(ctx, func(chunk *LogChunk) error {
+			if !yield(chunk, nil) {
+				return errStop
+			}
+			return nil
+		})
+		if err != nil && err != errStop {
+			yield(nil, err)
+		}
+	}
+}
+
+// fetch gets the logs of from..to, splitting the range while the provider rejects it as too large.
+func (s *LogScanner) fetch(ctx context.Context, from, to uint64) ([]ethtype.Log, error) {
+	q := s.q
+	q.FromBlock, q.ToBlock = new(big.Int).SetUint64(from), new(big.Int).SetUint64(to)
+	logs, err := s.ec.FilterLogs(ctx, q)
+	if err == nil {
+		s.grow()
+		return logs, nil
+	}
+	if from == to || !IsRangeTooLarge(err) {
+		return nil, err
+	}
+	s.shrink(to - from + 1)
+	mid := from + (to-from)/2
+	left, err := s.fetch(ctx, from, mid)
+	if err != nil {
+		return nil, err
+	}
+	right, err := s.fetch(ctx, mid+1, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// shrink halves the chunk size below a rejected size.
+func (s *LogScanner) shrink(rejected uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunkSize = max(1, min(s.chunkSize, rejected/2))
+}
+
+// grow increases the chunk size by 10% after a successful request.
+func (s *LogScanner) grow() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunkSize = min(s.cfg.MaxChunkSize, s.chunkSize+s.chunkSize/10+1)
+}
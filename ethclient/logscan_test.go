@@ -0,0 +1,134 @@
+package ethclient
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/donutnomad/eths/hexutil"
+	"github.com/ethereum/go-ethereum"
+)
+
+// logScanService has one log per block and rejects ranges of more than limit blocks.
+type logScanService struct {
+	head  uint64
+	limit uint64
+	fail  uint64 // block whose range fails with a non range error, 0 for none
+
+	mu       sync.Mutex
+	requests int
+}
+
+func (s *logScanService) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(s.head)
+}
+
+func (s *logScanService) GetLogs(crit map[string]any) ([]ethtype.Log, error) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+	from, _ := hexutil.DecodeUint64(crit["fromBlock"].(string))
+	to, _ := hexutil.DecodeUint64(crit["toBlock"].(string))
+	if to-from+1 > s.limit {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	if s.fail != 0 && from <= s.fail && s.fail <= to {
+		return nil, errors.New("internal error")
+	}
+	logs := []ethtype.Log{}
+	for n := from; n <= to; n++ {
+		logs = append(logs, ethtype.Log{BlockNumber: n, BlockHash: ecommon.Hash{byte(n), byte(n >> 8)}})
+	}
+	return logs, nil
+}
+
+func TestIsRangeTooLarge(t *testing.T) {
+	for _, msg := range []string{
+		"query returned more than 10000 results",
+		"Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range",
+		"eth_getLogs is limited to a 10,000 range",
+		"exceed maximum block range: 5000",
+	} {
+		if !IsRangeTooLarge(errors.New(msg)) {
+			t.Errorf("expected %q to be a range error", msg)
+		}
+	}
+	if IsRangeTooLarge(errors.New("execution reverted")) || IsRangeTooLarge(nil) {
+		t.Error("expected no range error")
+	}
+}
+
+func TestLogScanner(t *testing.T) {
+	service := &logScanService{head: 1000, limit: 70}
+	ec := newInProcClient(t, service)
+	s := NewLogScanner(ec, ethereum.FilterQuery{FromBlock: big.NewInt(5)}, LogScanConfig{ChunkSize: 200, MaxChunkSize: 300, Parallelism: 3})
+
+	next := uint64(5)
+	err := s.Scan(t.Context(), func(chunk *LogChunk) error {
+		if chunk.From != next {
+			t.Fatalf("expected chunk from %d, got %d", next, chunk.From)
+		}
+		for _, log := range chunk.Logs {
+			if log.BlockNumber != next {
+				t.Fatalf("expected log of block %d, got %d", next, log.BlockNumber)
+			}
+			next++
+		}
+		if next != chunk.To+1 {
+			t.Fatalf("expected chunk to %d, got %d", next-1, chunk.To)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 1001 {
+		t.Fatalf("expected logs up to block 1000, got %d", next-1)
+	}
+	if size := s.ChunkSize(); size >= 200 {
+		t.Fatalf("expected the chunk size to shrink, got %d", size)
+	}
+}
+
+func TestLogScannerError(t *testing.T) {
+	service := &logScanService{head: 1000, limit: 1000, fail: 450}
+	ec := newInProcClient(t, service)
+	s := NewLogScanner(ec, ethereum.FilterQuery{}, LogScanConfig{ChunkSize: 100, Parallelism: 2})
+
+	var last uint64
+	for chunk, err := range s.Chunks(t.Context()) {
+		if err != nil {
+			if IsRangeTooLarge(err) || last >= 450 {
+				t.Fatalf("unexpected error %v after block %d", err, last)
+			}
+			return
+		}
+		last = chunk.To
+	}
+	t.Fatal("expected an error")
+}
+
+func TestLogScannerStop(t *testing.T) {
+	service := &logScanService{head: 1000, limit: 1000}
+	ec := newInProcClient(t, service)
+	s := NewLogScanner(ec, ethereum.FilterQuery{ToBlock: big.NewInt(999)}, LogScanConfig{ChunkSize: 10, Parallelism: 2})
+
+	chunks := 0
+	for _, err := range s.Chunks(t.Context()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunks++; chunks == 3 {
+			break
+		}
+	}
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	// at most Parallelism chunks are pending or in flight besides the delivered ones
+	if service.requests > 3+2*2 {
+		t.Fatalf("expected the scan to stop, got %d requests", service.requests)
+	}
+}
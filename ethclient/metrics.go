@@ -0,0 +1,189 @@
+package ethclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram of NewMetricsCollector.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// MethodStats are the aggregated requests of one method, batches are aggregated as BatchMethod.
+type MethodStats struct {
+	Requests uint64
+	// Errors counts the failed requests by ErrorClass, failed batch elements are counted by the
+	// class of their error under their own method.
+	Errors map[string]uint64
+	// LatencyBuckets counts the requests by latency, LatencyBuckets[i] counts the requests that
+	// took at most Buckets[i] and the last element counts the slower ones.
+	LatencyBuckets []uint64
+	LatencySum     time.Duration
+	// BatchElements is the total size of the batches
+	BatchElements uint64
+	ResponseBytes uint64
+}
+
+// MetricsCollector is an Observer that aggregates requests per method in memory.
+type MetricsCollector struct {
+	buckets []time.Duration
+
+	mu      sync.Mutex
+	methods map[string]*MethodStats
+}
+
+// NewMetricsCollector returns a collector with the given latency buckets (DefaultLatencyBuckets
+// if empty), install it with WithObserver.
+func NewMetricsCollector(buckets ...time.Duration) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &MetricsCollector{buckets: buckets, methods: make(map[string]*MethodStats)}
+}
+
+// Buckets returns the upper bounds of the latency histogram.
+func (m *MetricsCollector) Buckets() []time.Duration {
+	return slices.Clone(m.buckets)
+}
+
+func (m *MetricsCollector) RequestStart(ctx context.Context, req *RequestInfo) context.Context {
+	return ctx
+}
+
+func (m *MetricsCollector) RequestEnd(ctx context.Context, req *RequestInfo, resp *ResponseInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.stats(req.Method)
+	s.Requests++
+	s.LatencySum += resp.Duration
+	s.LatencyBuckets[m.bucket(resp.Duration)]++
+	s.ResponseBytes += uint64(resp.ResponseBytes)
+	if resp.Err != nil {
+		s.Errors[resp.ErrorClass]++
+	}
+	if req.Methods != nil {
+		s.BatchElements += uint64(req.BatchSize)
+		for i, err := range resp.ElemErrors {
+			if err != nil {
+				m.stats(req.Methods[i]).Errors[ErrorClass(err)]++
+			}
+		}
+	}
+}
+
+// stats returns the stats of method, m.mu must be held.
+func (m *MetricsCollector) stats(method string) *MethodStats {
+	s, ok := m.methods[method]
+	if !ok {
+		s = &MethodStats{Errors: make(map[string]uint64), LatencyBuckets: make([]uint64, len(m.buckets)+1)}
+		m.methods[method] = s
+	}
+	return s
+}
+
+func (m *MetricsCollector) bucket(d time.Duration) int {
+	i, _ := slices.BinarySearch(m.buckets, d)
+	return i
+}
+
+// Snapshot returns a copy of the stats by method.
+func (m *MetricsCollector) Snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[string]MethodStats, len(m.methods))
+	for method, s := range m.methods {
+		c := *s
+		c.Errors = maps.Clone(s.Errors)
+		c.LatencyBuckets = slices.Clone(s.LatencyBuckets)
+		snapshot[method] = c
+	}
+	return snapshot
+}
+
+// Reset clears the collected stats.
+func (m *MetricsCollector) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.methods = make(map[string]*MethodStats)
+}
+
+// WritePrometheus writes the stats in the Prometheus text exposition format. Metric names are
+// prefixed with namespace, e.g. "myapp" gives myapp_rpc_requests_total.
+func (m *MetricsCollector) WritePrometheus(w io.Writer, namespace string) error {
+	prefix := "rpc_"
+	if namespace != "" {
+		prefix = namespace + "_rpc_"
+	}
+	snapshot := m.Snapshot()
+	methods := slices.Sorted(maps.Keys(snapshot))
+
+	var b strings.Builder
+	header := func(name, typ, help string) {
+		fmt.Fprintf(&b, "# HELP %s%s %s\n# TYPE %s%s %s\n", prefix, name, help, prefix, name, typ)
+	}
+
+	header("requests_total", "counter", "Number of JSON-RPC requests by method.")
+	for _, method := range methods {
+		fmt.Fprintf(&b, "%srequests_total{method=%q} %d\n", prefix, method, snapshot[method].Requests)
+	}
+
+	header("errors_total", "counter", "Number of failed JSON-RPC requests by method and error class.")
+	for _, method := range methods {
+		errs := snapshot[method].Errors
+		for _, class := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Fprintf(&b, "%serrors_total{method=%q,class=%q} %d\n", prefix, method, class, errs[class])
+		}
+	}
+
+	header("request_duration_seconds", "histogram", "Latency of JSON-RPC requests by method.")
+	for _, method := range methods {
+		s := snapshot[method]
+		var cumulative uint64
+		for i, count := range s.LatencyBuckets {
+			cumulative += count
+			le := "+Inf"
+			if i < len(m.buckets) {
+				le = strconv.FormatFloat(m.buckets[i].Seconds(), 'g', -1, 64)
+			}
+			fmt.Fprintf(&b, "%srequest_duration_seconds_bucket{method=%q,le=%q} %d\n", prefix, method, le, cumulative)
+		}
+		fmt.Fprintf(&b, "%srequest_duration_seconds_sum{method=%q} %s\n", prefix, method, strconv.FormatFloat(s.LatencySum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(&b, "%srequest_duration_seconds_count{method=%q} %d\n", prefix, method, cumulative)
+	}
+
+	header("batch_elements_total", "counter", "Number of calls sent in batch requests.")
+	for _, method := range methods {
+		if s := snapshot[method]; s.BatchElements > 0 {
+			fmt.Fprintf(&b, "%sbatch_elements_total{method=%q} %d\n", prefix, method, s.BatchElements)
+		}
+	}
+
+	header("response_bytes_total", "counter", "Size of the JSON-RPC results by method.")
+	for _, method := range methods {
+		fmt.Fprintf(&b, "%sresponse_bytes_total{method=%q} %d\n", prefix, method, snapshot[method].ResponseBytes)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PrometheusHandler serves the stats for a Prometheus scraper, see WritePrometheus.
+func (m *MetricsCollector) PrometheusHandler(namespace string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.WritePrometheus(w, namespace)
+	})
+}
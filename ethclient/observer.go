@@ -0,0 +1,170 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// BatchMethod is the RequestInfo.Method of batch requests.
+const BatchMethod = "batch"
+
+// RequestInfo describes a request sent to the endpoint. Retries are separate requests.
+type RequestInfo struct {
+	// Method is the method of a single call or BatchMethod
+	Method string
+	// Methods are the methods of a batch, nil for a single call
+	Methods []string
+	// BatchSize is 1 for a single call
+	BatchSize int
+	// Attempt counts from 0 and increases with every retry of the call
+	Attempt int
+}
+
+// ResponseInfo describes the outcome of a request.
+type ResponseInfo struct {
+	Duration time.Duration
+	// Err is the error of the request, for a batch the errors of the elements are in ElemErrors
+	Err error
+	// ErrorClass is ErrorClass(Err)
+	ErrorClass string
+	// ResponseBytes is the size of the JSON results
+	ResponseBytes int
+	// ElemErrors are the errors of the batch elements, nil for a single call
+	ElemErrors []error
+}
+
+// Observer is notified before and after every request sent by the Client, e.g. to collect
+// metrics or to start and end tracing spans. Implementations must be safe for concurrent use.
+type Observer interface {
+	// RequestStart is called before the request is sent, the returned context is used for the
+	// request and passed to RequestEnd.
+	RequestStart(ctx context.Context, req *RequestInfo) context.Context
+	RequestEnd(ctx context.Context, req *RequestInfo, resp *ResponseInfo)
+}
+
+// WithObserver notifies observers about every request.
+func WithObserver(observers ...Observer) Option {
+	return func(cfg *dialConfig) {
+		cfg.observers = append(cfg.observers, observers...)
+	}
+}
+
+// Error classes returned by ErrorClass, besides "http_<status>" and "rpc_<code>".
+const (
+	ErrorClassTimeout  = "timeout"
+	ErrorClassCanceled = "canceled"
+	ErrorClassNetwork  = "network"
+	ErrorClassOther    = "other"
+)
+
+// ErrorClass classifies a request error for metrics: "" for nil, "http_<status>" for HTTP errors,
+// "rpc_<code>" for JSON-RPC errors, or one of ErrorClassTimeout, ErrorClassCanceled,
+// ErrorClassNetwork and ErrorClassOther.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	if he, ok := AsHTTPError(err); ok {
+		return "http_" + strconv.Itoa(he.StatusCode)
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return "rpc_" + strconv.Itoa(rpcErr.ErrorCode())
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case netErr != nil:
+		return ErrorClassNetwork
+	}
+	return ErrorClassOther
+}
+
+// observeCall sends a single request and reports it to the observers.
+func (ec *Client) observeCall(ctx context.Context, attempt int, result any, method string, args ...any) error {
+	req := &RequestInfo{Method: method, BatchSize: 1, Attempt: attempt}
+	ctxs := ec.requestStart(ctx, req)
+
+	var raw json.RawMessage
+	target := result
+	if result != nil {
+		target = &raw
+	}
+	start := time.Now()
+	err := ec.send(ctxs[len(ctxs)-1], target, method, args...)
+	ec.requestEnd(ctxs, req, &ResponseInfo{
+		Duration:      time.Since(start),
+		Err:           err,
+		ErrorClass:    ErrorClass(err),
+		ResponseBytes: len(raw),
+	})
+	if err != nil || result == nil {
+		return err
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// observeBatch sends a batch request and reports it to the observers.
+func (ec *Client) observeBatch(ctx context.Context, attempt int, b []rpc.BatchElem) error {
+	req := &RequestInfo{Method: BatchMethod, Methods: make([]string, len(b)), BatchSize: len(b), Attempt: attempt}
+	for i, elem := range b {
+		req.Methods[i] = elem.Method
+	}
+	ctxs := ec.requestStart(ctx, req)
+
+	results := make([]any, len(b))
+	raws := make([]json.RawMessage, len(b))
+	for i := range b {
+		if results[i] = b[i].Result; results[i] != nil {
+			b[i].Result = &raws[i]
+		}
+	}
+	start := time.Now()
+	err := ec.sendBatch(ctxs[len(ctxs)-1], b)
+	resp := &ResponseInfo{Duration: time.Since(start), Err: err, ErrorClass: ErrorClass(err)}
+	if err == nil {
+		resp.ElemErrors = make([]error, len(b))
+	}
+	for i := range b {
+		b[i].Result = results[i]
+		resp.ResponseBytes += len(raws[i])
+		if err != nil {
+			continue
+		}
+		resp.ElemErrors[i] = b[i].Error
+		if b[i].Error == nil && results[i] != nil {
+			b[i].Error = json.Unmarshal(raws[i], results[i])
+		}
+	}
+	ec.requestEnd(ctxs, req, resp)
+	return err
+}
+
+// requestStart returns the context of every observer, the last one is used for the request.
+func (ec *Client) requestStart(ctx context.Context, req *RequestInfo) []context.Context {
+	ctxs := make([]context.Context, len(ec.observers)+1)
+	ctxs[0] = ctx
+	for i, o := range ec.observers {
+		ctx = o.RequestStart(ctx, req)
+		ctxs[i+1] = ctx
+	}
+	return ctxs
+}
+
+func (ec *Client) requestEnd(ctxs []context.Context, req *RequestInfo, resp *ResponseInfo) {
+	for i := len(ec.observers) - 1; i >= 0; i-- {
+		ec.observers[i].RequestEnd(ctxs[i+1], req, resp)
+	}
+}
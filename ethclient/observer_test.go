@@ -0,0 +1,113 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type observerService struct{}
+
+func (observerService) BlockNumber() hexutil.Uint64 {
+	return 0x10
+}
+
+func (observerService) GetCode(account ecommon.Address, block string) (hexutil.Bytes, error) {
+	if account == (ecommon.Address{}) {
+		return nil, errors.New("no code for the zero address")
+	}
+	return hexutil.Bytes{0x60, 0x80}, nil
+}
+
+type spanKey struct{}
+
+// spanObserver checks that the context returned by RequestStart reaches RequestEnd.
+type spanObserver struct {
+	ended []string
+}
+
+func (o *spanObserver) RequestStart(ctx context.Context, req *RequestInfo) context.Context {
+	return context.WithValue(ctx, spanKey{}, req.Method)
+}
+
+func (o *spanObserver) RequestEnd(ctx context.Context, req *RequestInfo, resp *ResponseInfo) {
+	o.ended = append(o.ended, fmt.Sprint(ctx.Value(spanKey{})))
+}
+
+func TestObserver(t *testing.T) {
+	metrics := NewMetricsCollector()
+	spans := &spanObserver{}
+	ec := newInProcClient(t, observerService{}, WithObserver(metrics, spans))
+
+	if n, err := ec.BlockNumber(t.Context()); err != nil || n != 0x10 {
+		t.Fatalf("unexpected block number %d (%v)", n, err)
+	}
+	if _, err := ec.CodeAt(t.Context(), ecommon.Address{}, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	var code hexutil.Bytes
+	var head hexutil.Uint64
+	batch := []rpc.BatchElem{
+		{Method: "eth_getCode", Args: []any{ecommon.Address{1}, "latest"}, Result: &code},
+		{Method: "eth_getCode", Args: []any{ecommon.Address{}, "latest"}, Result: new(hexutil.Bytes)},
+		{Method: "eth_blockNumber", Result: &head},
+	}
+	if err := ec.batchCallContext(t.Context(), batch); err != nil || len(code) != 2 || head != 0x10 || batch[1].Error == nil {
+		t.Fatalf("unexpected batch result %x %d (%v)", code, head, err)
+	}
+
+	stats := metrics.Snapshot()
+	if s := stats["eth_blockNumber"]; s.Requests != 1 || s.ResponseBytes != 6 || s.LatencyBuckets[0] != 1 {
+		t.Fatalf("unexpected eth_blockNumber stats %+v", s)
+	}
+	if s := stats["eth_getCode"]; s.Requests != 1 || s.Errors["rpc_-32000"] != 2 {
+		t.Fatalf("unexpected eth_getCode stats %+v", s)
+	}
+	if s := stats[BatchMethod]; s.Requests != 1 || s.BatchElements != 3 || len(s.Errors) != 0 {
+		t.Fatalf("unexpected batch stats %+v", s)
+	}
+	if got := strings.Join(spans.ended, ","); got != "eth_blockNumber,eth_getCode,batch" {
+		t.Fatalf("unexpected spans %s", got)
+	}
+
+	var b strings.Builder
+	if err := metrics.WritePrometheus(&b, "test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE test_rpc_requests_total counter",
+		`test_rpc_requests_total{method="eth_blockNumber"} 1`,
+		`test_rpc_errors_total{method="eth_getCode",class="rpc_-32000"} 2`,
+		`test_rpc_request_duration_seconds_bucket{method="batch",le="+Inf"} 1`,
+		`test_rpc_request_duration_seconds_count{method="eth_getCode"} 1`,
+		`test_rpc_batch_elements_total{method="batch"} 3`,
+		`test_rpc_response_bytes_total{method="eth_blockNumber"} 6`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Fatalf("missing %q in\n%s", line, b.String())
+		}
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{fmt.Errorf("call: %w", rpc.HTTPError{StatusCode: 429}), "http_429"},
+		{fmt.Errorf("call: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{context.Canceled, ErrorClassCanceled},
+		{errors.New("boom"), ErrorClassOther},
+	} {
+		if got := ErrorClass(tt.err); got != tt.want {
+			t.Fatalf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
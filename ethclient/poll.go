@@ -0,0 +1,192 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultPollInterval is the poll interval of PollNewHead and PollFilterLogs when none is given.
+const DefaultPollInterval = 4 * time.Second
+
+// WithPollingFallback makes SubscribeNewHead and SubscribeFilterLogs fall back to PollNewHead and
+// PollFilterLogs with the given interval when the endpoint doesn't support subscriptions (HTTP).
+func WithPollingFallback(interval time.Duration) Option {
+	return func(cfg *dialConfig) {
+		if interval <= 0 {
+			interval = DefaultPollInterval
+		}
+		cfg.pollInterval = interval
+	}
+}
+
+// canPoll reports whether a failed subscription should fall back to polling.
+func (ec *Client) canPoll(err error) bool {
+	return ec.pollInterval > 0 && errors.Is(err, rpc.ErrNotificationsUnsupported)
+}
+
+// PollNewHead emulates SubscribeNewHead by polling eth_blockNumber every interval and fetching
+// the new headers in order. A pool client sends all requests of the subscription to one endpoint,
+// so a head is never looked up on an endpoint that hasn't seen it yet. A failing request ends the
+// subscription with its error.
+func (ec *Client) PollNewHead(ctx context.Context, ch chan<- *ethtype.Header, interval time.Duration) (ethereum.Subscription, error) {
+	ec = ec.pinned()
+	last, err := ec.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pollSubscription(interval, nil, func(ctx context.Context) error {
+		head, err := ec.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		for ; last < head; last++ {
+			header, err := ec.HeaderByNumber(ctx, new(big.Int).SetUint64(last+1))
+			if err != nil {
+				return err
+			}
+			select {
+			case ch <- header:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}), nil
+}
+
+// PollFilterLogs emulates SubscribeFilterLogs by polling a filter installed with eth_newFilter
+// every interval. If the endpoint doesn't support filters or loses the filter, it continues with
+// FilterLogs over the new blocks. q.FromBlock, q.ToBlock and q.BlockHash are ignored, logs start
+// after the head at the time of the call. A pool client sends all requests of the subscription
+// to one endpoint, the one that holds the filter. A failing request ends the subscription with its error.
+func (ec *Client) PollFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethtype.Log, interval time.Duration) (ethereum.Subscription, error) {
+	q.FromBlock, q.ToBlock, q.BlockHash = nil, nil, nil
+	p := &logPoller{ec: ec.pinned(), q: q, ch: ch}
+	// the filter is installed before the head is read, so no block falls between the two
+	p.id, _ = p.newFilter(ctx)
+	head, err := p.ec.BlockNumber(ctx)
+	if err != nil {
+		p.close()
+		return nil, err
+	}
+	p.start, p.next = head+1, head+1
+	return pollSubscription(interval, p.close, p.poll), nil
+}
+
+type logPoller struct {
+	ec    *Client
+	q     ethereum.FilterQuery
+	ch    chan<- ethtype.Log
+	id    string // filter id, empty once filters are not usable
+	start uint64 // first block of the subscription, the filter may return older logs
+	next  uint64 // first block not delivered yet
+}
+
+func (p *logPoller) newFilter(ctx context.Context) (string, error) {
+	arg, err := toFilterArg(p.q)
+	if err != nil {
+		return "", err
+	}
+	delete(arg.(map[string]any), "fromBlock")
+	return Call[string](p.ec, ctx, "eth_newFilter", arg)
+}
+
+func (p *logPoller) poll(ctx context.Context) error {
+	if p.id != "" {
+		logs, err := Call[[]ethtype.Log](p.ec, ctx, "eth_getFilterChanges", p.id)
+		if err == nil {
+			return p.deliver(ctx, logs)
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		// the filter expired or is unknown to the node that served the request
+		_, _ = Call[bool](p.ec, ctx, "eth_uninstallFilter", p.id)
+		p.id = ""
+	}
+
+	head, err := p.ec.BlockNumber(ctx)
+	if err != nil || head < p.next {
+		return err
+	}
+	q := p.q
+	q.FromBlock, q.ToBlock = new(big.Int).SetUint64(p.next), new(big.Int).SetUint64(head)
+	logs, err := p.ec.FilterLogs(ctx, q)
+	if err != nil {
+		return err
+	}
+	if err := p.deliver(ctx, logs); err != nil {
+		return err
+	}
+	p.next = head + 1
+	return nil
+}
+
+func (p *logPoller) deliver(ctx context.Context, logs []ethtype.Log) error {
+	for _, log := range logs {
+		if log.BlockNumber < p.start {
+			continue
+		}
+		select {
+		case p.ch <- log:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !log.Removed {
+			p.next = max(p.next, log.BlockNumber+1)
+		}
+	}
+	return nil
+}
+
+// close uninstalls the filter when the subscription ends.
+func (p *logPoller) close() {
+	if p.id != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = Call[bool](p.ec, ctx, "eth_uninstallFilter", p.id)
+	}
+}
+
+// pollSubscription runs poll every interval until the subscription is unsubscribed or poll fails,
+// then it calls stop if not nil.
+func pollSubscription(interval time.Duration, stop func(), poll func(ctx context.Context) error) ethereum.Subscription {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		if stop != nil {
+			defer stop()
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-quit:
+				return nil
+			}
+			done := make(chan error, 1)
+			go func() { done <- poll(ctx) }()
+			select {
+			case err := <-done:
+				if err != nil {
+					return err
+				}
+			case <-quit:
+				cancel()
+				<-done
+				return nil
+			}
+		}
+	})
+}
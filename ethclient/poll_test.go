@@ -0,0 +1,239 @@
+package ethclient
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/donutnomad/eths/hexutil"
+	"github.com/ethereum/go-ethereum"
+)
+
+// pollService is an HTTP-only chain with one log per block, its filter is lost after two polls.
+type pollService struct {
+	mu        sync.Mutex
+	head      uint64
+	installed bool
+	polls     int
+	polled    uint64
+	ranged    int
+	removed   bool
+}
+
+func (s *pollService) mine() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.head++
+}
+
+func (s *pollService) log(n uint64) ethtype.Log {
+	return ethtype.Log{BlockNumber: n, BlockHash: ecommon.Hash{byte(n)}}
+}
+
+func (s *pollService) BlockNumber() hexutil.Uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return hexutil.Uint64(s.head)
+}
+
+func (s *pollService) GetBlockByNumber(tag string, full bool) map[string]any {
+	n, _ := hexutil.DecodeUint64(tag)
+	if n > uint64(s.BlockNumber()) {
+		return nil
+	}
+	return map[string]any{"number": hexutil.EncodeUint64(n), "hash": ecommon.Hash{byte(n)}}
+}
+
+func (s *pollService) NewFilter(crit map[string]any) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.installed = true
+	s.polled = s.head
+	return "0x1"
+}
+
+func (s *pollService) GetFilterChanges(id string) ([]ethtype.Log, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.installed {
+		return nil, errors.New("filter not found")
+	}
+	if s.polls++; s.polls > 2 {
+		return nil, errors.New("filter not found")
+	}
+	logs := []ethtype.Log{}
+	for ; s.polled < s.head; s.polled++ {
+		logs = append(logs, s.log(s.polled+1))
+	}
+	return logs, nil
+}
+
+func (s *pollService) UninstallFilter(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = true
+	return true
+}
+
+func (s *pollService) GetLogs(crit map[string]any) []ethtype.Log {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranged++
+	from, _ := hexutil.DecodeUint64(crit["fromBlock"].(string))
+	to, _ := hexutil.DecodeUint64(crit["toBlock"].(string))
+	logs := []ethtype.Log{}
+	for n := from; n <= to; n++ {
+		logs = append(logs, s.log(n))
+	}
+	return logs
+}
+
+func newPollTestClient(t *testing.T) (*Client, *pollService) {
+	t.Helper()
+	service := &pollService{head: 10}
+	url := newHTTPTestServer(t, service, nil)
+	ec, err := DialContext(t.Context(), url, WithPollingFallback(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ec.Close)
+	return ec, service
+}
+
+func TestPollNewHead(t *testing.T) {
+	ec, service := newPollTestClient(t)
+	ch := make(chan *ethtype.Header)
+	sub, err := ec.SubscribeNewHead(t.Context(), ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	service.mine()
+	service.mine()
+	for _, want := range []uint64{11, 12} {
+		select {
+		case head := <-ch:
+			if head.Number.Uint64() != want {
+				t.Fatalf("expected head %d, got %d", want, head.Number)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for head %d", want)
+		}
+	}
+}
+
+func TestPollNewHead_Pool(t *testing.T) {
+	var services []*pollService
+	var endpoints []Endpoint
+	for range 2 {
+		ec, service := newPollTestClient(t)
+		services = append(services, service)
+		endpoints = append(endpoints, Endpoint{Client: ec})
+	}
+	ec, err := NewPoolClient(endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan *ethtype.Header)
+	sub, err := ec.PollNewHead(t.Context(), ch, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// the second endpoint lags behind, its head must not be asked for a block only the first one has
+	services[0].mine()
+	time.Sleep(100 * time.Millisecond)
+	services[1].mine()
+	select {
+	case head := <-ch:
+		if head.Number.Uint64() != 11 {
+			t.Fatalf("expected head 11, got %d", head.Number)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for head 11")
+	}
+}
+
+func TestPollFilterLogs(t *testing.T) {
+	ec, service := newPollTestClient(t)
+	ch := make(chan ethtype.Log)
+	sub, err := ec.SubscribeFilterLogs(t.Context(), ethereum.FilterQuery{}, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// two logs from the filter, then the filter is lost and logs come from eth_getLogs
+	for _, want := range []uint64{11, 12, 13, 14} {
+		service.mine()
+		select {
+		case log := <-ch:
+			if log.BlockNumber != want {
+				t.Fatalf("expected log of block %d, got %d", want, log.BlockNumber)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for block %d", want)
+		}
+	}
+	sub.Unsubscribe()
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if !service.removed || service.ranged == 0 {
+		t.Fatalf("expected the filter to be uninstalled and eth_getLogs to be used, got %+v", service)
+	}
+}
+
+func TestPollFilterLogs_Pool(t *testing.T) {
+	var services []*pollService
+	var endpoints []Endpoint
+	for range 2 {
+		ec, service := newPollTestClient(t)
+		services = append(services, service)
+		endpoints = append(endpoints, Endpoint{Client: ec})
+	}
+	ec, err := NewPoolClient(endpoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan ethtype.Log)
+	sub, err := ec.PollFilterLogs(t.Context(), ethereum.FilterQuery{}, ch, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// the chains of both endpoints move together, only the one holding the filter is polled
+	for _, want := range []uint64{11, 12} {
+		for _, service := range services {
+			service.mine()
+		}
+		select {
+		case log := <-ch:
+			if log.BlockNumber != want {
+				t.Fatalf("expected log of block %d, got %d", want, log.BlockNumber)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for block %d", want)
+		}
+	}
+	for i, service := range services {
+		service.mu.Lock()
+		if service.ranged != 0 || service.installed != (service.polls > 0) {
+			t.Errorf("endpoint %d: expected the filter to be polled where it was installed, got %+v", i, service)
+		}
+		service.mu.Unlock()
+	}
+}
@@ -0,0 +1,436 @@
+package ethclient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/samber/lo"
+)
+
+var ErrNoEndpoints = errors.New("pool has no endpoints")
+
+// Strategy selects the endpoint a pool client sends a request to first.
+type Strategy int
+
+const (
+	// RoundRobin rotates over the healthy endpoints.
+	RoundRobin Strategy = iota
+	// Weighted distributes requests proportionally to Endpoint.Weight (smooth weighted round-robin).
+	Weighted
+	// LowestLatency prefers the endpoint with the lowest moving average latency.
+	LowestLatency
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case RoundRobin:
+		return "round-robin"
+	case Weighted:
+		return "weighted"
+	case LowestLatency:
+		return "lowest-latency"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// Endpoint is one upstream of a pool client.
+type Endpoint struct {
+	Name   string
+	Client *Client
+	// Weight is used by the Weighted strategy, values <= 0 count as 1
+	Weight int
+}
+
+// EndpointStatus is a snapshot of the health of an endpoint.
+type EndpointStatus struct {
+	Name         string
+	Healthy      bool
+	EjectedUntil time.Time
+	Failures     int
+	Latency      time.Duration
+	OverloadRate float64
+}
+
+// PoolOption configures a pool client.
+type PoolOption func(*poolConfig)
+
+type poolConfig struct {
+	strategy      Strategy
+	maxFailures   int
+	ejectDuration time.Duration
+	maxAttempts   int
+	dialOpts      []Option
+}
+
+// WithStrategy sets the endpoint selection strategy, the default is RoundRobin.
+func WithStrategy(s Strategy) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.strategy = s
+	}
+}
+
+// WithEjection ejects an endpoint for d after maxFailures consecutive failures, the default is 3 failures for 30s.
+// An endpoint that reports IsOverloaded or answers with Retry-After is ejected immediately.
+func WithEjection(maxFailures int, d time.Duration) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.maxFailures = max(maxFailures, 1)
+		cfg.ejectDuration = d
+	}
+}
+
+// WithMaxAttempts limits how many endpoints an idempotent request is tried on, the default is all of them.
+func WithMaxAttempts(n int) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.maxAttempts = n
+	}
+}
+
+// WithDialOptions sets the options DialPool dials every endpoint with.
+func WithDialOptions(opts ...Option) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.dialOpts = append(cfg.dialOpts, opts...)
+	}
+}
+
+// Pool spreads the requests of a Client over several endpoints.
+//
+// Idempotent requests that fail because of the endpoint (transport errors, HTTP 429/5xx)
+// are retried on the next endpoint. Non idempotent requests (e.g. eth_sendRawTransaction)
+// are sent exactly once. JSON-RPC errors such as reverts are returned as they are.
+type Pool struct {
+	cfg       poolConfig
+	endpoints []*poolEndpoint
+	next      atomic.Uint64
+	mu        sync.Mutex
+}
+
+type poolEndpoint struct {
+	name   string
+	client *Client
+	weight int
+
+	current int // smooth weighted round-robin state, guarded by Pool.mu
+
+	mu           sync.Mutex
+	failures     int
+	ejectedUntil time.Time
+	latency      time.Duration
+}
+
+// NewPoolClient returns a Client that spreads its requests over endpoints.
+func NewPoolClient(endpoints []Endpoint, opts ...PoolOption) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	cfg := poolConfig{
+		strategy:      RoundRobin,
+		maxFailures:   3,
+		ejectDuration: 30 * time.Second,
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	p := &Pool{cfg: cfg}
+	for i, e := range endpoints {
+		if e.Client == nil {
+			return nil, fmt.Errorf("endpoint %d has no client", i)
+		}
+		p.endpoints = append(p.endpoints, &poolEndpoint{
+			name:   lo.Ternary(e.Name != "", e.Name, fmt.Sprintf("endpoint-%d", i)),
+			client: e.Client,
+			weight: max(e.Weight, 1),
+		})
+	}
+	return &Client{pool: p, od: newOverloadDetector(50, 0.5)}, nil
+}
+
+// DialPool dials every url with the options of WithDialOptions and returns a pool client over them.
+func DialPool(ctx context.Context, urls []string, opts ...PoolOption) (*Client, error) {
+	var cfg poolConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	endpoints := make([]Endpoint, 0, len(urls))
+	for _, url := range urls {
+		c, err := DialContext(ctx, url, cfg.dialOpts...)
+		if err != nil {
+			for _, e := range endpoints {
+				e.Client.Close()
+			}
+			return nil, fmt.Errorf("dial %s: %w", url, err)
+		}
+		endpoints = append(endpoints, Endpoint{Name: url, Client: c})
+	}
+	return NewPoolClient(endpoints, opts...)
+}
+
+// Status returns the health of every endpoint.
+func (p *Pool) Status() []EndpointStatus {
+	now := time.Now()
+	ret := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		ret = append(ret, EndpointStatus{
+			Name:         e.name,
+			Healthy:      !now.Before(e.ejectedUntil),
+			EjectedUntil: e.ejectedUntil,
+			Failures:     e.failures,
+			Latency:      e.latency,
+			OverloadRate: e.client.OverloadRate(),
+		})
+		e.mu.Unlock()
+	}
+	return ret
+}
+
+func (p *Pool) close() {
+	for _, e := range p.endpoints {
+		e.client.Close()
+	}
+}
+
+// order returns the endpoints in the order a request should try them: the endpoint picked by
+// the strategy, the other healthy endpoints by latency, then the ejected ones by the time they
+// come back. Ejected endpoints are only used when no healthy endpoint is left.
+func (p *Pool) order() []*poolEndpoint {
+	now := time.Now()
+	var healthy, ejected []*poolEndpoint
+	for _, e := range p.endpoints {
+		if e.healthy(now) {
+			healthy = append(healthy, e)
+		} else {
+			ejected = append(ejected, e)
+		}
+	}
+	slices.SortStableFunc(ejected, func(a, b *poolEndpoint) int {
+		return a.ejectedAt().Compare(b.ejectedAt())
+	})
+	if len(healthy) == 0 {
+		return ejected
+	}
+
+	first := p.pick(healthy)
+	rest := slices.DeleteFunc(slices.Clone(healthy), func(e *poolEndpoint) bool { return e == first })
+	slices.SortStableFunc(rest, func(a, b *poolEndpoint) int {
+		return cmp.Compare(a.avgLatency(), b.avgLatency())
+	})
+	return append(append([]*poolEndpoint{first}, rest...), ejected...)
+}
+
+func (p *Pool) pick(healthy []*poolEndpoint) *poolEndpoint {
+	switch p.cfg.strategy {
+	case Weighted:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		var best *poolEndpoint
+		total := 0
+		for _, e := range healthy {
+			e.current += e.weight
+			total += e.weight
+			if best == nil || e.current > best.current {
+				best = e
+			}
+		}
+		best.current -= total
+		return best
+	case LowestLatency:
+		// endpoints without samples go first so that they get measured
+		return slices.MinFunc(healthy, func(a, b *poolEndpoint) int {
+			return cmp.Compare(a.avgLatency(), b.avgLatency())
+		})
+	default:
+		return healthy[(p.next.Add(1)-1)%uint64(len(healthy))]
+	}
+}
+
+func (p *Pool) attempts(n int) int {
+	if p.cfg.maxAttempts > 0 {
+		return min(p.cfg.maxAttempts, n)
+	}
+	return n
+}
+
+func (p *Pool) callContext(ctx context.Context, result any, method string, args ...any) error {
+	return p.do(ctx, IsIdempotent(method), func(c *Client) error {
+		return c.callContext(ctx, result, method, args...)
+	})
+}
+
+func (p *Pool) batchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	idempotent := true
+	for _, elem := range b {
+		idempotent = idempotent && IsIdempotent(elem.Method)
+	}
+	return p.do(ctx, idempotent, func(c *Client) error {
+		for i := range b {
+			b[i].Error = nil
+		}
+		return c.batchCallContext(ctx, b)
+	})
+}
+
+func (p *Pool) do(ctx context.Context, retry bool, fn func(c *Client) error) error {
+	endpoints := p.order()
+	if !retry {
+		endpoints = endpoints[:1]
+	}
+	var err error
+	for _, e := range endpoints[:p.attempts(len(endpoints))] {
+		start := time.Now()
+		err = fn(e.client)
+		if errors.Is(err, ErrRateLimitExceeded) {
+			// the endpoint is over its client side budget, it isn't unhealthy
+			continue
+		}
+		if !isEndpointError(ctx, err) {
+			e.succeed(time.Since(start))
+			return err
+		}
+		e.fail(err, p.cfg.maxFailures, p.cfg.ejectDuration)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return err
+}
+
+// rpcClient returns the client of the preferred endpoint.
+func (p *Pool) rpcClient() *rpc.Client {
+	return p.preferred().Client()
+}
+
+// preferred returns the endpoint a request would be sent to first.
+func (p *Pool) preferred() *Client {
+	return p.order()[0].client
+}
+
+// pinned returns the client that requests depending on node state, such as the ones of an
+// installed filter, must all be sent to. That is ec itself unless ec is a pool client.
+func (ec *Client) pinned() *Client {
+	if ec.pool == nil {
+		return ec
+	}
+	return ec.pool.preferred()
+}
+
+// ethSubscribe subscribes on the first endpoint that supports subscriptions.
+func (p *Pool) ethSubscribe(ctx context.Context, channel any, args ...any) (*rpc.ClientSubscription, error) {
+	var err error
+	for _, e := range p.order() {
+		var sub *rpc.ClientSubscription
+		if sub, err = e.client.ethSubscribe(ctx, channel, args...); err == nil {
+			return sub, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+func (e *poolEndpoint) healthy(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !now.Before(e.ejectedUntil)
+}
+
+func (e *poolEndpoint) ejectedAt() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ejectedUntil
+}
+
+func (e *poolEndpoint) avgLatency() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.latency
+}
+
+func (e *poolEndpoint) succeed(latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures = 0
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		// exponential moving average, alpha = 0.3
+		e.latency = (e.latency*7 + latency*3) / 10
+	}
+}
+
+func (e *poolEndpoint) fail(err error, maxFailures int, ejectDuration time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+	d := time.Duration(0)
+	if e.failures >= maxFailures || e.client.IsOverloaded() {
+		d = ejectDuration
+	}
+	if retryAfter := RetryAfter(err); retryAfter > d {
+		d = retryAfter
+	}
+	if d > 0 {
+		e.ejectedUntil = time.Now().Add(d)
+	}
+}
+
+// isEndpointError reports whether err was caused by the endpoint rather than the request,
+// so that the request may succeed on another endpoint. Only transport failures, 429 and 5xx
+// responses and "limit exceeded" answers count, any other error passes through unchanged.
+func isEndpointError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, ErrRateLimitExceeded) {
+		return false
+	}
+	if he, ok := AsHTTPError(err); ok {
+		return he.StatusCode == http.StatusTooManyRequests || he.StatusCode >= 500
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005: limit exceeded, the rest are answers to the request itself
+		return rpcErr.ErrorCode() == -32005
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED):
+		return true
+	}
+	// transports that flatten the error into a string
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset") || strings.Contains(msg, "connection refused")
+}
+
+// IsIdempotent reports whether sending the JSON-RPC method twice has the same effect as
+// sending it once, i.e. whether it may be retried.
+func IsIdempotent(method string) bool {
+	switch method {
+	case "eth_sendRawTransaction", "eth_sendRawTransactionSync", "eth_sendTransaction",
+		"eth_sign", "eth_signTransaction", "eth_signTypedData", "eth_newFilter",
+		"eth_newBlockFilter", "eth_newPendingTransactionFilter", "eth_uninstallFilter",
+		"eth_getFilterChanges", "eth_subscribe", "eth_unsubscribe":
+		return false
+	}
+	for _, prefix := range []string{"personal_", "admin_", "miner_", "account_", "clique_", "engine_"} {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type poolTestService struct {
+	calls atomic.Int64
+	sent  atomic.Int64
+}
+
+func (s *poolTestService) BlockNumber() string {
+	s.calls.Add(1)
+	return "0x10"
+}
+
+func (s *poolTestService) SendRawTransaction(string) (string, error) {
+	s.sent.Add(1)
+	return "", errors.New("nonce too low")
+}
+
+func newTestTx() *types.Transaction {
+	return types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000})
+}
+
+// newPoolTestEndpoint serves the eth namespace over HTTP, failing with status while *failing is set.
+func newPoolTestEndpoint(t *testing.T, name string, failing *atomic.Int32) (*poolTestService, Endpoint) {
+	t.Helper()
+	service := &poolTestService{}
+	url := newHTTPTestServer(t, service, func(w http.ResponseWriter, r *http.Request) bool {
+		if failing == nil || failing.Load() == 0 {
+			return false
+		}
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "unavailable", int(failing.Load()))
+		return true
+	})
+	c, err := DialContext(t.Context(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service, Endpoint{Name: name, Client: c}
+}
+
+func TestPool_RoundRobin(t *testing.T) {
+	a, ea := newPoolTestEndpoint(t, "a", nil)
+	b, eb := newPoolTestEndpoint(t, "b", nil)
+	ec, err := NewPoolClient([]Endpoint{ea, eb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	for range 4 {
+		if n, err := ec.BlockNumber(t.Context()); err != nil || n != 0x10 {
+			t.Fatalf("unexpected block number %d (%v)", n, err)
+		}
+	}
+	if a.calls.Load() != 2 || b.calls.Load() != 2 {
+		t.Fatalf("expected 2 calls each, got a=%d b=%d", a.calls.Load(), b.calls.Load())
+	}
+}
+
+func TestPool_Weighted(t *testing.T) {
+	a, ea := newPoolTestEndpoint(t, "a", nil)
+	b, eb := newPoolTestEndpoint(t, "b", nil)
+	ea.Weight = 3
+	ec, err := NewPoolClient([]Endpoint{ea, eb}, WithStrategy(Weighted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	for range 8 {
+		if _, err := ec.BlockNumber(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.calls.Load() != 6 || b.calls.Load() != 2 {
+		t.Fatalf("expected a=6 b=2, got a=%d b=%d", a.calls.Load(), b.calls.Load())
+	}
+}
+
+func TestPool_Failover(t *testing.T) {
+	var failing atomic.Int32
+	failing.Store(http.StatusServiceUnavailable)
+	_, ea := newPoolTestEndpoint(t, "a", &failing)
+	b, eb := newPoolTestEndpoint(t, "b", nil)
+	ec, err := NewPoolClient([]Endpoint{ea, eb}, WithEjection(1, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	for range 3 {
+		if _, err := ec.BlockNumber(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.calls.Load() != 3 {
+		t.Fatalf("expected every call to reach b, got %d", b.calls.Load())
+	}
+	status := ec.Pool().Status()
+	if status[0].Healthy || status[0].Failures != 1 || !status[1].Healthy {
+		t.Fatalf("expected a to be ejected after one failure: %+v", status)
+	}
+
+	// a JSON-RPC error is an answer, not an endpoint failure
+	if err := ec.SendTransaction(t.Context(), newTestTx()); err == nil {
+		t.Fatal("expected the rpc error")
+	}
+	if b.sent.Load() != 1 || !ec.Pool().Status()[1].Healthy {
+		t.Fatal("expected one send on b without ejection")
+	}
+}
+
+func TestPool_NoRetryForSend(t *testing.T) {
+	var failing atomic.Int32
+	failing.Store(http.StatusBadGateway)
+	a, ea := newPoolTestEndpoint(t, "a", &failing)
+	b, eb := newPoolTestEndpoint(t, "b", nil)
+	ec, err := NewPoolClient([]Endpoint{ea, eb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	err = ec.SendTransaction(t.Context(), newTestTx())
+	if !IsHTTPStatus(err, http.StatusBadGateway) {
+		t.Fatalf("expected the 502 of the first endpoint, got %v", err)
+	}
+	if a.sent.Load() != 0 || b.sent.Load() != 0 {
+		t.Fatal("a failed send must not be retried on another endpoint")
+	}
+}
+
+func TestPool_OverloadEjectsWithRetryAfter(t *testing.T) {
+	var failing atomic.Int32
+	failing.Store(http.StatusTooManyRequests)
+	_, ea := newPoolTestEndpoint(t, "a", &failing)
+	_, eb := newPoolTestEndpoint(t, "b", nil)
+	ec, err := NewPoolClient([]Endpoint{ea, eb}, WithEjection(10, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	if _, err := ec.BlockNumber(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	status := ec.Pool().Status()[0]
+	if status.Healthy || time.Until(status.EjectedUntil) <= 0 || status.OverloadRate != 1 {
+		t.Fatalf("expected a to be ejected by Retry-After: %+v", status)
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	for method, want := range map[string]bool{
+		"eth_call":               true,
+		"eth_getLogs":            true,
+		"eth_sendRawTransaction": false,
+		"personal_unlockAccount": false,
+	} {
+		if IsIdempotent(method) != want {
+			t.Errorf("IsIdempotent(%s) != %v", method, want)
+		}
+	}
+}
+
+func TestIsEndpointError(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		want bool
+	}{
+		"refused":      {&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+		"reset":        {fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		"eof":          {io.ErrUnexpectedEOF, true},
+		"503":          {rpc.HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		"429":          {rpc.HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		"400":          {rpc.HTTPError{StatusCode: http.StatusBadRequest}, false},
+		"revert":       {errors.New("execution reverted"), false},
+		"decode":       {&json.SyntaxError{}, false},
+		"deadline":     {context.DeadlineExceeded, false},
+		"rate limited": {ErrRateLimitExceeded, false},
+	} {
+		if got := isEndpointError(t.Context(), tc.err); got != tc.want {
+			t.Errorf("%s: isEndpointError(%v) = %v, want %v", name, tc.err, got, tc.want)
+		}
+	}
+}
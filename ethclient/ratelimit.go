@@ -0,0 +1,170 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrRateLimitExceeded = errors.New("client rate limit exceeded")
+
+// DefaultMethodCosts is a compute unit table modeled after the plans of common providers.
+// Methods that are not listed cost DefaultMethodCost.
+var DefaultMethodCosts = map[string]float64{
+	"eth_chainId":                           0,
+	"net_version":                           0,
+	"eth_blockNumber":                       10,
+	"eth_gasPrice":                          20,
+	"eth_maxPriorityFeePerGas":              10,
+	"eth_blobBaseFee":                       10,
+	"eth_getBalance":                        20,
+	"eth_getCode":                           20,
+	"eth_getStorageAt":                      20,
+	"eth_getTransactionCount":               25,
+	"eth_getBlockByNumber":                  20,
+	"eth_getBlockByHash":                    20,
+	"eth_getTransactionByHash":              20,
+	"eth_getTransactionByBlockHashAndIndex": 20,
+	"eth_getTransactionReceipt":             20,
+	"eth_getBlockReceipts":                  500,
+	"eth_call":                              30,
+	"eth_estimateGas":                       90,
+	"eth_createAccessList":                  90,
+	"eth_feeHistory":                        10,
+	"eth_getLogs":                           75,
+	"eth_sendRawTransaction":                250,
+	"eth_sendRawTransactionSync":            250,
+	"debug_traceTransaction":                300,
+	"debug_traceCall":                       300,
+}
+
+// DefaultMethodCost is the cost of a method that is missing from the cost table.
+const DefaultMethodCost = 20
+
+// RateLimiter is a token bucket measured in compute units. Every request takes the cost of its
+// method from the bucket, the bucket refills with unitsPerSecond up to burst.
+//
+// A RateLimiter may be shared by several clients that use the same provider plan.
+type RateLimiter struct {
+	// FailFast makes requests fail with ErrRateLimitExceeded instead of waiting for budget.
+	FailFast bool
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	costs  map[string]float64
+	now    func() time.Time
+}
+
+// NewRateLimiter returns a limiter with a full bucket. costs defaults to DefaultMethodCosts.
+// A unitsPerSecond of 0 or less means unlimited, Wait never blocks.
+func NewRateLimiter(unitsPerSecond, burst float64, costs map[string]float64) *RateLimiter {
+	if costs == nil {
+		costs = DefaultMethodCosts
+	}
+	return &RateLimiter{
+		rate:   unitsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		costs:  costs,
+		now:    time.Now,
+	}
+}
+
+// WithRateLimiter limits the requests of the client with l.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(cfg *dialConfig) {
+		cfg.limiter = l
+	}
+}
+
+// Cost returns the compute units of method.
+func (l *RateLimiter) Cost(method string) float64 {
+	if cost, ok := l.costs[method]; ok {
+		return cost
+	}
+	return DefaultMethodCost
+}
+
+// BatchCost returns the compute units of all requests of b.
+func (l *RateLimiter) BatchCost(b []rpc.BatchElem) float64 {
+	var cost float64
+	for _, elem := range b {
+		cost += l.Cost(elem.Method)
+	}
+	return cost
+}
+
+// Available returns the compute units currently in the bucket, negative while requests wait.
+func (l *RateLimiter) Available() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill()
+	return l.tokens
+}
+
+// Wait takes cost from the bucket, blocking until it is available or ctx is done.
+// With FailFast it returns ErrRateLimitExceeded instead of blocking.
+//
+// A cost larger than the burst is allowed once the bucket is full and leaves it in debt.
+func (l *RateLimiter) Wait(ctx context.Context, cost float64) error {
+	if cost <= 0 || l.rate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	l.refill()
+	if l.FailFast && l.tokens < cost && l.tokens < l.burst {
+		available := l.tokens
+		l.mu.Unlock()
+		return fmt.Errorf("%w: need %g units, %g available", ErrRateLimitExceeded, cost, available)
+	}
+	l.tokens -= cost
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens += cost
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *RateLimiter) refill() {
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+}
+
+func (l *RateLimiter) waitMethod(ctx context.Context, method string) error {
+	if l == nil {
+		return nil
+	}
+	return l.Wait(ctx, l.Cost(method))
+}
+
+func (l *RateLimiter) waitBatch(ctx context.Context, b []rpc.BatchElem) error {
+	if l == nil {
+		return nil
+	}
+	return l.Wait(ctx, l.BatchCost(b))
+}
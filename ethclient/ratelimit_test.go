@@ -0,0 +1,99 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestRateLimiter_Refill(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(100, 50, map[string]float64{"eth_call": 30})
+	l.now = func() time.Time { return now }
+	l.FailFast = true
+
+	if err := l.Wait(t.Context(), l.Cost("eth_call")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Wait(t.Context(), l.Cost("eth_call")); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected ErrRateLimitExceeded, got %v", err)
+	}
+	if got := l.Available(); got != 20 {
+		t.Fatalf("a rejected request must not take units, available %g", got)
+	}
+	now = now.Add(100 * time.Millisecond)
+	if err := l.Wait(t.Context(), l.Cost("eth_call")); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	if got := l.Available(); got != 50 {
+		t.Fatalf("expected a full bucket of 50, got %g", got)
+	}
+	// larger than the burst, allowed on a full bucket
+	if err := l.Wait(t.Context(), l.BatchCost([]rpc.BatchElem{{Method: "eth_call"}, {Method: "eth_call"}})); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Available(); got != -10 {
+		t.Fatalf("expected a debt of 10 units, got %g", got)
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	l := NewRateLimiter(0, 0, nil)
+	l.FailFast = true
+	for range 3 {
+		if err := l.Wait(t.Context(), 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRateLimiter_Blocking(t *testing.T) {
+	l := NewRateLimiter(1000, 10, nil)
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(t.Context(), 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("expected to wait about 20ms, waited %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, 1000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if got := l.Available(); got < -10 {
+		t.Fatalf("a canceled wait must return its units, available %g", got)
+	}
+}
+
+func TestRateLimiter_Client(t *testing.T) {
+	requests, url := newRetryTestServer(t, 0, 0, "")
+	l := NewRateLimiter(1, 25, nil)
+	l.FailFast = true
+	ec, err := DialContext(t.Context(), url, WithRateLimiter(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	if _, err := ec.BlockNumber(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	batch := []rpc.BatchElem{
+		{Method: "eth_blockNumber", Result: new(string)},
+		{Method: "eth_blockNumber", Result: new(string)},
+	}
+	if err := ec.batchCallContext(t.Context(), batch); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected the batch to be charged 20 units, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("expected 1 request, got %d", requests.Load())
+	}
+}
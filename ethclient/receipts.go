@@ -0,0 +1,111 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrReceiptsMismatch is returned by the BlockReceipts fallback when the receipts fetched one by
+// one don't belong to the block, e.g. because of a reorg between the requests.
+var ErrReceiptsMismatch = errors.New("receipts don't match the block")
+
+// ReceiptsBatchSize is the number of eth_getTransactionReceipt requests per batch of the
+// BlockReceipts fallback, providers commonly reject larger batches.
+const ReceiptsBatchSize = 100
+
+// methodUnsupported reports whether err says that the endpoint doesn't implement method. sure is
+// set for the JSON-RPC code -32601, an error message alone may also come from a proxy or a
+// failing backend and only counts for the request it answered.
+func methodUnsupported(err error, method string) (unsupported, sure bool) {
+	if err == nil {
+		return false, false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true, true
+	}
+	msg := strings.ToLower(err.Error())
+	method = strings.ToLower(method)
+	for _, m := range []string{
+		"method not found",
+		"the method " + method + " does not exist",
+		"method " + method + " not supported",
+	} {
+		if strings.Contains(msg, m) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// blockReceiptsByTx fetches the receipts of a block's transactions in batches of ReceiptsBatchSize.
+func blockReceiptsByTx[T any](ctx context.Context, ec *Client, blockNrOrHash ethtype.BlockNumberOrHash) ([]T, error) {
+	var block *ethtype.LiteBlock
+	var err error
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		block, err = ec.LiteBlockByHash(ctx, hash)
+	} else {
+		number, _ := blockNrOrHash.Number()
+		block, err = ec.LiteBlockByNumber(ctx, big.NewInt(number.Int64()))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	raws := make([]json.RawMessage, len(block.Transactions))
+	batch := make([]rpc.BatchElem, len(block.Transactions))
+	for i, hash := range block.Transactions {
+		batch[i] = rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []any{hash}, Result: &raws[i]}
+	}
+	for chunk := range slices.Chunk(batch, ReceiptsBatchSize) {
+		if err := ec.batchCallContext(ctx, chunk); err != nil {
+			return nil, err
+		}
+	}
+	receipts := make([]T, len(batch))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, elem.Error
+		}
+		if len(raws[i]) == 0 || string(raws[i]) == "null" {
+			return nil, fmt.Errorf("%w: no receipt for transaction %s", ErrReceiptsMismatch, block.Transactions[i])
+		}
+		if err := json.Unmarshal(raws[i], &receipts[i]); err != nil {
+			return nil, err
+		}
+	}
+	if typed, ok := any(receipts).([]*ethtype.TxReceipt); ok {
+		if err := checkBlockReceipts(block, typed); err != nil {
+			return nil, err
+		}
+	}
+	return receipts, nil
+}
+
+// checkBlockReceipts checks that receipts are the receipts of block's transactions in order.
+func checkBlockReceipts(block *ethtype.LiteBlock, receipts []*ethtype.TxReceipt) error {
+	if len(receipts) != len(block.Transactions) {
+		return fmt.Errorf("%w: %d receipts for %d transactions", ErrReceiptsMismatch, len(receipts), len(block.Transactions))
+	}
+	var cumulative uint64
+	for i, r := range receipts {
+		switch {
+		case r.BlockHash != block.Hash:
+			return fmt.Errorf("%w: receipt %d is in block %s, not %s", ErrReceiptsMismatch, i, r.BlockHash, block.Hash)
+		case r.TxHash != block.Transactions[i] || r.TransactionIndex != uint(i):
+			return fmt.Errorf("%w: receipt %d is of transaction %s at index %d", ErrReceiptsMismatch, i, r.TxHash, r.TransactionIndex)
+		case r.CumulativeGasUsed < cumulative:
+			return fmt.Errorf("%w: cumulative gas used of receipt %d decreases", ErrReceiptsMismatch, i)
+		}
+		cumulative = r.CumulativeGasUsed
+	}
+	return nil
+}
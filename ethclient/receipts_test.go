@@ -0,0 +1,142 @@
+package ethclient
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/donutnomad/eths/hexutil"
+)
+
+// receiptsService has a block with three transactions and no eth_getBlockReceipts.
+type receiptsService struct {
+	block ecommon.Hash
+	txs   []ecommon.Hash
+	// reorged is the block hash returned in the last receipt
+	reorged *ecommon.Hash
+}
+
+func (s *receiptsService) GetBlockByNumber(tag string, full bool) map[string]any {
+	return map[string]any{"number": tag, "hash": s.block, "transactions": s.txs}
+}
+
+func (s *receiptsService) GetTransactionReceipt(hash ecommon.Hash) map[string]any {
+	for i, tx := range s.txs {
+		if tx != hash {
+			continue
+		}
+		block := s.block
+		if s.reorged != nil && i == len(s.txs)-1 {
+			block = *s.reorged
+		}
+		return map[string]any{
+			"transactionHash":   tx,
+			"transactionIndex":  hexutil.EncodeUint64(uint64(i)),
+			"blockHash":         block,
+			"cumulativeGasUsed": hexutil.EncodeUint64(21000 * uint64(i+1)),
+		}
+	}
+	return nil
+}
+
+func TestBlockReceiptsFallback(t *testing.T) {
+	service := &receiptsService{block: ecommon.Hash{1}, txs: []ecommon.Hash{{10}, {11}, {12}}}
+	metrics := NewMetricsCollector()
+	ec := newInProcClient(t, service, WithObserver(metrics))
+
+	block := ethtype.BlockNumberOrHashWithNumber(5)
+	for range 2 {
+		receipts, err := ec.BlockReceipts(t.Context(), block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(receipts) != 3 || receipts[2].TxHash != service.txs[2] || receipts[2].CumulativeGasUsed != 63000 {
+			t.Fatalf("unexpected receipts %+v", receipts)
+		}
+	}
+	stats := metrics.Snapshot()
+	if stats["eth_getBlockReceipts"].Requests != 1 || stats[BatchMethod].BatchElements != 6 {
+		t.Fatalf("expected one eth_getBlockReceipts request and two batches, got %+v", stats)
+	}
+
+	service.reorged = &ecommon.Hash{2}
+	if _, err := ec.BlockReceipts(t.Context(), block); !errors.Is(err, ErrReceiptsMismatch) {
+		t.Fatalf("expected ErrReceiptsMismatch, got %v", err)
+	}
+}
+
+// receiptsProxyService answers eth_getBlockReceipts like a proxy that can't reach a backend with it.
+type receiptsProxyService struct {
+	*receiptsService
+}
+
+func (s receiptsProxyService) GetBlockReceipts(tag string) ([]map[string]any, error) {
+	return nil, errors.New("method eth_getBlockReceipts not supported by upstream")
+}
+
+func TestBlockReceiptsFallback_MessageOnly(t *testing.T) {
+	service := &receiptsService{block: ecommon.Hash{1}}
+	for i := range ReceiptsBatchSize + 1 {
+		service.txs = append(service.txs, ecommon.BigToHash(big.NewInt(int64(i+10))))
+	}
+	metrics := NewMetricsCollector()
+	ec := newInProcClient(t, receiptsProxyService{service}, WithObserver(metrics))
+
+	for range 2 {
+		receipts, err := ec.BlockReceipts(t.Context(), ethtype.BlockNumberOrHashWithNumber(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(receipts) != len(service.txs) {
+			t.Fatalf("expected %d receipts, got %d", len(service.txs), len(receipts))
+		}
+	}
+	stats := metrics.Snapshot()
+	if stats["eth_getBlockReceipts"].Requests != 2 || stats[BatchMethod].Requests != 4 {
+		t.Fatalf("expected eth_getBlockReceipts every time and two batches per fallback, got %+v", stats)
+	}
+}
+
+// receiptsFullService serves eth_getBlockReceipts.
+type receiptsFullService struct {
+	*receiptsService
+}
+
+func (s receiptsFullService) GetBlockReceipts(tag string) []map[string]any {
+	var receipts []map[string]any
+	for _, tx := range s.txs {
+		receipts = append(receipts, s.GetTransactionReceipt(tx))
+	}
+	return receipts
+}
+
+func TestBlockReceiptsFallback_Pool(t *testing.T) {
+	service := &receiptsService{block: ecommon.Hash{1}, txs: []ecommon.Hash{{10}, {11}}}
+	without, with := NewMetricsCollector(), NewMetricsCollector()
+	ec, err := NewPoolClient([]Endpoint{
+		{Client: newInProcClient(t, service, WithObserver(without))},
+		{Client: newInProcClient(t, receiptsFullService{service}, WithObserver(with))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 4 {
+		receipts, err := ec.BlockReceipts(t.Context(), ethtype.BlockNumberOrHashWithNumber(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(receipts) != len(service.txs) {
+			t.Fatalf("expected %d receipts, got %d", len(service.txs), len(receipts))
+		}
+	}
+	// only the endpoint without the method falls back, the other one keeps serving it
+	if stats := without.Snapshot(); stats["eth_getBlockReceipts"].Requests != 1 || stats[BatchMethod].Requests != 2 {
+		t.Fatalf("expected one eth_getBlockReceipts request and a batch per fallback, got %+v", stats)
+	}
+	if stats := with.Snapshot(); stats["eth_getBlockReceipts"].Requests != 2 || stats[BatchMethod].Requests != 0 {
+		t.Fatalf("expected eth_getBlockReceipts on every call, got %+v", stats)
+	}
+}
@@ -0,0 +1,401 @@
+package ethclient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// ErrBackfillTooLarge is returned by a resilient subscription when the blocks missed while
+// disconnected exceed ResubscribeConfig.MaxBackfill.
+var ErrBackfillTooLarge = errors.New("backfill range too large")
+
+// resubscribeLogChunk is the block range of one eth_getLogs request during a backfill.
+const resubscribeLogChunk = 1000
+
+// ResubscribeConfig configures the resilient subscriptions.
+type ResubscribeConfig struct {
+	// MinBackoff and MaxBackoff bound the exponential delay between reconnects, the defaults are 1s and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// ReorgDepth is the number of recent blocks remembered to detect orphaned items, the default is 64.
+	ReorgDepth uint64
+	// MaxBackfill is the max number of missed blocks backfilled after a reconnect, 0 means no limit.
+	// The subscription fails with ErrBackfillTooLarge when the gap is larger.
+	MaxBackfill uint64
+}
+
+func (c *ResubscribeConfig) backoff(attempt int) time.Duration {
+	minBackoff, maxBackoff := c.MinBackoff, c.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = time.Second
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	d := minBackoff << min(attempt, 16)
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+func (c *ResubscribeConfig) reorgDepth() uint64 {
+	if c.ReorgDepth == 0 {
+		return 64
+	}
+	return c.ReorgDepth
+}
+
+// SubscribeNewHeadResilient is like SubscribeNewHead, but reconnects with backoff when the
+// subscription fails and delivers the heads missed in between. Every head is delivered once.
+// Heads orphaned while disconnected are not repeated, the heads of the new chain follow instead.
+func (ec *Client) SubscribeNewHeadResilient(ctx context.Context, ch chan<- *ethtype.Header, cfg ResubscribeConfig) (ethereum.Subscription, error) {
+	return subscribeResilient(ctx, ec, ch, cfg, resilientSource[*ethtype.Header]{
+		subscribe: func(ctx context.Context, ch chan<- *ethtype.Header) (ethereum.Subscription, error) {
+			return ec.SubscribeNewHead(ctx, ch)
+		},
+		backfill: func(ctx context.Context, from, to uint64, emit func(*ethtype.Header) error) error {
+			for n := from; n <= to; n++ {
+				head, err := ec.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+				if err != nil {
+					return err
+				}
+				if err := emit(head); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		position: func(head *ethtype.Header) (uint64, ecommon.Hash, uint, bool) {
+			if head == nil || head.Number == nil {
+				return 0, ecommon.Hash{}, 0, false
+			}
+			return head.Number.Uint64(), head.Hash, 0, true
+		},
+		removed: func(*ethtype.Header) bool { return false },
+		markRemoved: func(*ethtype.Header) (*ethtype.Header, bool) {
+			return nil, false
+		},
+	})
+}
+
+// SubscribeFilterLogsResilient is like SubscribeFilterLogs, but reconnects with backoff when the
+// subscription fails and backfills the missed blocks with FilterLogs. Logs are delivered once and
+// in order. Delivered logs whose block was orphaned while disconnected are delivered again with
+// Removed set. q.FromBlock, q.ToBlock and q.BlockHash must be nil.
+func (ec *Client) SubscribeFilterLogsResilient(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethtype.Log, cfg ResubscribeConfig) (ethereum.Subscription, error) {
+	if q.FromBlock != nil || q.ToBlock != nil || q.BlockHash != nil {
+		return nil, errors.New("resilient log subscriptions don't support a block range")
+	}
+	return subscribeResilient(ctx, ec, ch, cfg, resilientSource[ethtype.Log]{
+		subscribe: func(ctx context.Context, ch chan<- ethtype.Log) (ethereum.Subscription, error) {
+			return ec.SubscribeFilterLogs(ctx, q, ch)
+		},
+		backfill: func(ctx context.Context, from, to uint64, emit func(ethtype.Log) error) error {
+			for start := from; start <= to; start += resubscribeLogChunk {
+				query := q
+				query.FromBlock = new(big.Int).SetUint64(start)
+				query.ToBlock = new(big.Int).SetUint64(min(start+resubscribeLogChunk-1, to))
+				logs, err := ec.FilterLogs(ctx, query)
+				if err != nil {
+					return err
+				}
+				for _, log := range logs {
+					if err := emit(log); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		position: func(log ethtype.Log) (uint64, ecommon.Hash, uint, bool) {
+			return log.BlockNumber, log.BlockHash, log.Index, true
+		},
+		removed: func(log ethtype.Log) bool { return log.Removed },
+		markRemoved: func(log ethtype.Log) (ethtype.Log, bool) {
+			log.Removed = true
+			return log, true
+		},
+	})
+}
+
+// SubscribeTransactionReceiptsResilient is like SubscribeTransactionReceipts, but reconnects
+// with backoff when the subscription fails and backfills the missed blocks with BlockReceipts.
+// Every block's receipts are delivered once and in order. Receipts have no removed flag, so
+// receipts orphaned while disconnected are not repeated, those of the new chain follow instead.
+func (ec *Client) SubscribeTransactionReceiptsResilient(ctx context.Context, q *ethereum.TransactionReceiptsQuery, ch chan<- []*ethtype.Receipt, cfg ResubscribeConfig) (ethereum.Subscription, error) {
+	var wanted map[ecommon.Hash]bool
+	if q != nil && len(q.TransactionHashes) > 0 {
+		wanted = make(map[ecommon.Hash]bool, len(q.TransactionHashes))
+		for _, hash := range q.TransactionHashes {
+			wanted[ecommon.Hash(hash)] = true
+		}
+	}
+	return subscribeResilient(ctx, ec, ch, cfg, resilientSource[[]*ethtype.Receipt]{
+		subscribe: func(ctx context.Context, ch chan<- []*ethtype.Receipt) (ethereum.Subscription, error) {
+			return ec.SubscribeTransactionReceipts(ctx, q, ch)
+		},
+		backfill: func(ctx context.Context, from, to uint64, emit func([]*ethtype.Receipt) error) error {
+			for n := from; n <= to; n++ {
+				receipts, err := ec.BlockReceipts(ctx, ethtype.BlockNumberOrHashWithNumber(ethtype.BlockNumber(n)))
+				if err != nil {
+					return err
+				}
+				if wanted != nil {
+					receipts = slices.DeleteFunc(receipts, func(r *ethtype.Receipt) bool { return !wanted[r.TxHash] })
+				}
+				if len(receipts) > 0 {
+					if err := emit(receipts); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		position: func(receipts []*ethtype.Receipt) (uint64, ecommon.Hash, uint, bool) {
+			if len(receipts) == 0 || receipts[0].BlockNumber == nil {
+				return 0, ecommon.Hash{}, 0, false
+			}
+			return receipts[0].BlockNumber.Uint64(), receipts[0].BlockHash, 0, true
+		},
+		removed: func([]*ethtype.Receipt) bool { return false },
+		markRemoved: func([]*ethtype.Receipt) ([]*ethtype.Receipt, bool) {
+			return nil, false
+		},
+	})
+}
+
+// resilientSource adapts one kind of subscription to the resubscribe loop.
+type resilientSource[T any] struct {
+	subscribe func(ctx context.Context, ch chan<- T) (ethereum.Subscription, error)
+	// backfill emits the items of the canonical blocks from..to in order
+	backfill func(ctx context.Context, from, to uint64, emit func(T) error) error
+	// position returns the block of an item and its index in the block, false for items to drop
+	position    func(item T) (number uint64, hash ecommon.Hash, index uint, ok bool)
+	removed     func(item T) bool
+	markRemoved func(item T) (T, bool)
+}
+
+// deliveredBlock is a recent block and the items delivered from it.
+type deliveredBlock[T any] struct {
+	number uint64
+	hash   ecommon.Hash
+	items  []T
+	seen   map[uint]bool
+}
+
+type resilientStream[T any] struct {
+	ec  *Client
+	src resilientSource[T]
+	cfg ResubscribeConfig
+	out chan<- T
+
+	blocks []*deliveredBlock[T] // ascending by number
+	next   uint64               // first block that may not be fully delivered
+}
+
+func subscribeResilient[T any](ctx context.Context, ec *Client, out chan<- T, cfg ResubscribeConfig, src resilientSource[T]) (ethereum.Subscription, error) {
+	live := make(chan T, 128)
+	sub, err := src.subscribe(ctx, live)
+	if err != nil {
+		return nil, err
+	}
+	// the head at subscription time is where backfills start from
+	head, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	s := &resilientStream[T]{ec: ec, src: src, cfg: cfg, out: out, next: head.Number.Uint64() + 1}
+	s.add(head.Number.Uint64(), head.Hash)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-quit:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		return s.run(ctx, sub, live)
+	}), nil
+}
+
+func (s *resilientStream[T]) run(ctx context.Context, sub ethereum.Subscription, live chan T) error {
+	err := s.consume(ctx, sub, live)
+	for attempt := 0; ; attempt++ {
+		if sub != nil {
+			sub.Unsubscribe()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrBackfillTooLarge) {
+			return err
+		}
+		select {
+		case <-time.After(s.cfg.backoff(attempt)):
+		case <-ctx.Done():
+			return nil
+		}
+		live = make(chan T, 128)
+		if sub, err = s.src.subscribe(ctx, live); err != nil {
+			sub = nil
+			continue
+		}
+		if err = s.sync(ctx); err == nil {
+			attempt = -1
+			err = s.consume(ctx, sub, live)
+		}
+	}
+}
+
+// consume forwards the live items until the subscription fails.
+func (s *resilientStream[T]) consume(ctx context.Context, sub ethereum.Subscription, live <-chan T) error {
+	for {
+		select {
+		case item := <-live:
+			if err := s.handle(ctx, item); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *resilientStream[T]) handle(ctx context.Context, item T) error {
+	if !s.src.removed(item) {
+		return s.deliver(ctx, item)
+	}
+	// only forward removals of items the consumer has seen
+	_, hash, index, ok := s.src.position(item)
+	if !ok {
+		return nil
+	}
+	b := s.find(hash)
+	if b == nil || !b.seen[index] {
+		return nil
+	}
+	delete(b.seen, index)
+	b.items = slices.DeleteFunc(b.items, func(it T) bool {
+		_, _, i, _ := s.src.position(it)
+		return i == index
+	})
+	return s.emit(ctx, item)
+}
+
+// deliver emits item unless it was delivered already.
+func (s *resilientStream[T]) deliver(ctx context.Context, item T) error {
+	number, hash, index, ok := s.src.position(item)
+	if !ok {
+		return nil
+	}
+	b := s.find(hash)
+	if b != nil && b.seen[index] {
+		return nil
+	}
+	if err := s.emit(ctx, item); err != nil {
+		return err
+	}
+	if b == nil {
+		b = s.add(number, hash)
+	}
+	b.seen[index] = true
+	b.items = append(b.items, item)
+	s.next = max(s.next, number)
+	return nil
+}
+
+func (s *resilientStream[T]) emit(ctx context.Context, item T) error {
+	select {
+	case s.out <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sync runs after every reconnect: it removes the delivered items of orphaned blocks and
+// backfills the blocks missed since the last delivery.
+func (s *resilientStream[T]) sync(ctx context.Context) error {
+	head, err := s.ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	headNumber := head.Number.Uint64()
+	from := s.next
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		b := s.blocks[i]
+		canonical, err := s.ec.HeaderByNumber(ctx, new(big.Int).SetUint64(b.number))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return err
+		}
+		if err == nil && canonical.Hash == b.hash {
+			break
+		}
+		for j := len(b.items) - 1; j >= 0; j-- {
+			if removed, ok := s.src.markRemoved(b.items[j]); ok {
+				if err := s.emit(ctx, removed); err != nil {
+					return err
+				}
+			}
+		}
+		s.blocks = s.blocks[:i]
+		from = min(from, b.number)
+	}
+
+	if from <= headNumber {
+		if s.cfg.MaxBackfill > 0 && headNumber-from+1 > s.cfg.MaxBackfill {
+			return fmt.Errorf("%w: blocks %d..%d", ErrBackfillTooLarge, from, headNumber)
+		}
+		if err := s.src.backfill(ctx, from, headNumber, func(item T) error { return s.deliver(ctx, item) }); err != nil {
+			return err
+		}
+	}
+	if s.find(head.Hash) == nil {
+		s.add(headNumber, head.Hash)
+	}
+	s.next = max(s.next, headNumber+1)
+	return nil
+}
+
+func (s *resilientStream[T]) find(hash ecommon.Hash) *deliveredBlock[T] {
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		if s.blocks[i].hash == hash {
+			return s.blocks[i]
+		}
+	}
+	return nil
+}
+
+// add remembers a block and forgets the blocks deeper than ReorgDepth.
+func (s *resilientStream[T]) add(number uint64, hash ecommon.Hash) *deliveredBlock[T] {
+	b := &deliveredBlock[T]{number: number, hash: hash, seen: make(map[uint]bool)}
+	i, _ := slices.BinarySearchFunc(s.blocks, number, func(b *deliveredBlock[T], n uint64) int {
+		return cmp.Compare(b.number, n+1)
+	})
+	s.blocks = slices.Insert(s.blocks, i, b)
+	if newest := s.blocks[len(s.blocks)-1].number; newest >= s.cfg.reorgDepth() {
+		s.blocks = slices.DeleteFunc(s.blocks, func(b *deliveredBlock[T]) bool {
+			return b.number <= newest-s.cfg.reorgDepth()
+		})
+	}
+	return b
+}
@@ -0,0 +1,251 @@
+package ethclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/ethtype"
+	"github.com/donutnomad/eths/hexutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// resubChain is a fake chain whose blocks can be replaced to simulate reorgs.
+type resubChain struct {
+	mu       sync.Mutex
+	hashes   []ecommon.Hash
+	logs     map[ecommon.Hash][]ethtype.Log
+	logSubs  map[*rpc.Notifier]*rpc.Subscription
+	headSubs map[*rpc.Notifier]*rpc.Subscription
+}
+
+func newResubChain(height int) *resubChain {
+	c := &resubChain{
+		logs:     make(map[ecommon.Hash][]ethtype.Log),
+		logSubs:  make(map[*rpc.Notifier]*rpc.Subscription),
+		headSubs: make(map[*rpc.Notifier]*rpc.Subscription),
+	}
+	for n := range height + 1 {
+		c.hashes = append(c.hashes, ecommon.Hash{0: byte(n), 1: 0})
+	}
+	return c
+}
+
+// setBlock replaces or appends block n of fork with the given number of logs, it returns the logs.
+func (c *resubChain) setBlock(n uint64, fork byte, logCount int) []ethtype.Log {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hash := ecommon.Hash{0: byte(n), 1: fork}
+	c.hashes = append(c.hashes[:n], hash)
+	var logs []ethtype.Log
+	for i := range logCount {
+		logs = append(logs, ethtype.Log{BlockNumber: n, BlockHash: hash, Index: uint(i), Data: []byte{fork}})
+	}
+	c.logs[hash] = logs
+	return logs
+}
+
+func (c *resubChain) header(n uint64) map[string]any {
+	head := map[string]any{"number": hexutil.EncodeUint64(n), "hash": c.hashes[n]}
+	if n > 0 {
+		head["parentHash"] = c.hashes[n-1]
+	}
+	return head
+}
+
+// notify sends the head and the logs of block n to the subscribers.
+func (c *resubChain) notify(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for notifier, sub := range c.headSubs {
+		_ = notifier.Notify(sub.ID, c.header(n))
+	}
+	for notifier, sub := range c.logSubs {
+		for _, log := range c.logs[c.hashes[n]] {
+			_ = notifier.Notify(sub.ID, log)
+		}
+	}
+}
+
+func (c *resubChain) subscribers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.logSubs) + len(c.headSubs)
+}
+
+type resubService struct {
+	chain *resubChain
+}
+
+func (s *resubService) GetBlockByNumber(tag string, full bool) map[string]any {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+	n := uint64(len(s.chain.hashes) - 1)
+	if tag != "latest" {
+		if n2, err := hexutil.DecodeUint64(tag); err != nil || n2 > n {
+			return nil
+		} else {
+			n = n2
+		}
+	}
+	return s.chain.header(n)
+}
+
+func (s *resubService) GetLogs(crit map[string]any) []ethtype.Log {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+	from, _ := hexutil.DecodeUint64(crit["fromBlock"].(string))
+	to, _ := hexutil.DecodeUint64(crit["toBlock"].(string))
+	logs := []ethtype.Log{}
+	for n := from; n <= to && n < uint64(len(s.chain.hashes)); n++ {
+		logs = append(logs, s.chain.logs[s.chain.hashes[n]]...)
+	}
+	return logs
+}
+
+func (s *resubService) subscribe(ctx context.Context, subs map[*rpc.Notifier]*rpc.Subscription) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	s.chain.mu.Lock()
+	subs[notifier] = sub
+	s.chain.mu.Unlock()
+	go func() {
+		<-sub.Err()
+		s.chain.mu.Lock()
+		delete(subs, notifier)
+		s.chain.mu.Unlock()
+	}()
+	return sub, nil
+}
+
+func (s *resubService) Logs(ctx context.Context, crit map[string]any) (*rpc.Subscription, error) {
+	return s.subscribe(ctx, s.chain.logSubs)
+}
+
+func (s *resubService) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return s.subscribe(ctx, s.chain.headSubs)
+}
+
+// newResubTestClient serves chain over websocket, the returned function drops all connections.
+func newResubTestClient(t *testing.T, chain *resubChain) (*Client, func()) {
+	t.Helper()
+	var current atomic.Pointer[rpc.Server]
+	newServer := func() {
+		server := newTestServer(t, map[string]any{"eth": &resubService{chain: chain}})
+		if old := current.Swap(server); old != nil {
+			old.Stop()
+		}
+	}
+	newServer()
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current.Load().WebsocketHandler([]string{"*"}).ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	ec, err := DialContext(t.Context(), "ws"+strings.TrimPrefix(httpServer.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ec.Close)
+	return ec, newServer
+}
+
+func waitSubscribers(t *testing.T, chain *resubChain, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for chain.subscribers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d subscribers, got %d", n, chain.subscribers())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T, n int) []T {
+	t.Helper()
+	var items []T
+	for range n {
+		select {
+		case item := <-ch:
+			items = append(items, item)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout after %d of %d items", len(items), n)
+		}
+	}
+	select {
+	case item := <-ch:
+		t.Fatalf("unexpected item %v", item)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return items
+}
+
+func TestSubscribeFilterLogsResilient(t *testing.T) {
+	chain := newResubChain(10)
+	ec, restart := newResubTestClient(t, chain)
+	cfg := ResubscribeConfig{MinBackoff: 10 * time.Millisecond}
+
+	ch := make(chan ethtype.Log)
+	sub, err := ec.SubscribeFilterLogsResilient(t.Context(), ethereum.FilterQuery{}, ch, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	waitSubscribers(t, chain, 1)
+
+	orphaned := chain.setBlock(11, 0, 2)
+	chain.notify(11)
+	receive(t, ch, 2)
+
+	// block 11 is replaced and block 12 is mined while disconnected
+	replaced := chain.setBlock(11, 1, 1)
+	mined := chain.setBlock(12, 1, 1)
+	restart()
+	got := receive(t, ch, 4)
+	want := []ethtype.Log{orphaned[1], orphaned[0], replaced[0], mined[0]}
+	want[0].Removed, want[1].Removed = true, true
+	for i := range want {
+		if got[i].BlockHash != want[i].BlockHash || got[i].Index != want[i].Index || got[i].Removed != want[i].Removed {
+			t.Fatalf("log %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	// live logs that were backfilled already are not repeated
+	waitSubscribers(t, chain, 1)
+	chain.notify(12)
+	next := chain.setBlock(13, 1, 1)
+	chain.notify(13)
+	if got := receive(t, ch, 1); got[0].BlockHash != next[0].BlockHash {
+		t.Fatalf("expected the log of block 13, got %+v", got[0])
+	}
+}
+
+func TestSubscribeNewHeadResilient(t *testing.T) {
+	chain := newResubChain(10)
+	ec, restart := newResubTestClient(t, chain)
+
+	ch := make(chan *ethtype.Header)
+	sub, err := ec.SubscribeNewHeadResilient(t.Context(), ch, ResubscribeConfig{MinBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	waitSubscribers(t, chain, 1)
+
+	chain.setBlock(11, 0, 0)
+	chain.notify(11)
+	chain.setBlock(12, 0, 0)
+	chain.setBlock(13, 0, 0)
+	restart()
+	for i, head := range receive(t, ch, 3) {
+		if head.Number.Uint64() != uint64(11+i) {
+			t.Fatalf("head %d: unexpected number %d", i, head.Number)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ethclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries requests that failed because of rate limiting
+// (HTTP 429, JSON-RPC -32005) or transient transport errors (connection errors, HTTP 5xx).
+//
+// Non idempotent methods (see IsIdempotent) are only retried when they are listed in MethodRetries.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// MethodRetries overrides MaxRetries per method, 0 disables retries of the method.
+	MethodRetries map[string]int
+	// BaseDelay is the backoff before the first retry, it doubles with every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A longer Retry-After is still honored.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy retries 3 times with a backoff from 200ms up to 10s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  200 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+	}
+}
+
+// WithRetry enables retries of failed requests, see RetryPolicy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(cfg *dialConfig) {
+		cfg.retry = &policy
+	}
+}
+
+// retries returns how often method may be retried.
+func (p *RetryPolicy) retries(method string) int {
+	if p == nil {
+		return 0
+	}
+	if n, ok := p.MethodRetries[method]; ok {
+		return n
+	}
+	if !IsIdempotent(method) {
+		return 0
+	}
+	return p.MaxRetries
+}
+
+// delay returns the wait before retry number attempt (0 based): the Retry-After of err if any,
+// else an exponential backoff with jitter in [d/2, d].
+func (p *RetryPolicy) delay(attempt int, err error) time.Duration {
+	if d := RetryAfter(err); d > 0 {
+		return d
+	}
+	d := p.BaseDelay << min(attempt, 30)
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retry calls fn until it succeeds, fails with an error that is not worth retrying or
+// retries are exhausted.
+func (ec *Client) retry(ctx context.Context, retries int, fn func(attempt int) error) error {
+	err := fn(0)
+	for attempt := 0; attempt < retries && isEndpointError(ctx, err); attempt++ {
+		d := ec.retryPolicy.delay(attempt, err)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return err
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = fn(attempt + 1)
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package ethclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryTestServer fails the first `failures` requests with status and a Retry-After of retryAfter.
+func newRetryTestServer(t *testing.T, failures int64, status int, retryAfter string) (*atomic.Int64, string) {
+	t.Helper()
+	var requests atomic.Int64
+	url := newHTTPTestServer(t, &poolTestService{}, func(w http.ResponseWriter, r *http.Request) bool {
+		if requests.Add(1) > failures {
+			return false
+		}
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		http.Error(w, "try again", status)
+		return true
+	})
+	return &requests, url
+}
+
+func testRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+}
+
+func TestRetry_Transient(t *testing.T) {
+	requests, url := newRetryTestServer(t, 2, http.StatusServiceUnavailable, "")
+	ec, err := DialContext(t.Context(), url, WithRetry(testRetryPolicy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	if n, err := ec.BlockNumber(t.Context()); err != nil || n != 0x10 {
+		t.Fatalf("unexpected block number %d (%v)", n, err)
+	}
+	if requests.Load() != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests.Load())
+	}
+}
+
+func TestRetry_Exhausted(t *testing.T) {
+	requests, url := newRetryTestServer(t, 10, http.StatusTooManyRequests, "")
+	policy := testRetryPolicy()
+	policy.MethodRetries = map[string]int{"eth_blockNumber": 1}
+	ec, err := DialContext(t.Context(), url, WithRetry(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	if _, err := ec.BlockNumber(t.Context()); !IsRateLimited(err) {
+		t.Fatalf("expected a 429, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestRetry_NonIdempotent(t *testing.T) {
+	requests, url := newRetryTestServer(t, 10, http.StatusTooManyRequests, "")
+	ec, err := DialContext(t.Context(), url, WithRetry(testRetryPolicy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	if err := ec.SendTransaction(t.Context(), newTestTx()); !IsRateLimited(err) {
+		t.Fatalf("expected a 429, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("eth_sendRawTransaction must not be retried, got %d requests", requests.Load())
+	}
+
+	policy := testRetryPolicy()
+	policy.MethodRetries = map[string]int{"eth_sendRawTransaction": 2}
+	ec2, err := DialContext(t.Context(), url, WithRetry(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec2.Close()
+	requests.Store(0)
+	_ = ec2.SendTransaction(t.Context(), newTestTx())
+	if requests.Load() != 3 {
+		t.Fatalf("expected 3 requests when enabled explicitly, got %d", requests.Load())
+	}
+}
+
+func TestRetry_RetryAfter(t *testing.T) {
+	requests, url := newRetryTestServer(t, 1, http.StatusTooManyRequests, "1")
+	ec, err := DialContext(t.Context(), url, WithRetry(testRetryPolicy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ec.Close()
+
+	start := time.Now()
+	if _, err := ec.BlockNumber(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || requests.Load() != 2 {
+		t.Fatalf("expected to wait for Retry-After, waited %s with %d requests", elapsed, requests.Load())
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		if d := p.delay(attempt, nil); d < want/2 || d > want {
+			t.Fatalf("attempt %d: delay %s not in [%s, %s]", attempt, d, want/2, want)
+		}
+	}
+}
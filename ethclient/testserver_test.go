@@ -0,0 +1,45 @@
+package ethclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestServer returns a server with services registered by namespace, it is stopped when the test ends.
+func newTestServer(t *testing.T, services map[string]any) *rpc.Server {
+	t.Helper()
+	server := rpc.NewServer()
+	for namespace, service := range services {
+		if err := server.RegisterName(namespace, service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(server.Stop)
+	return server
+}
+
+// newInProcClient returns a client connected in process to a server with service as the eth namespace.
+func newInProcClient(t *testing.T, service any, opts ...Option) *Client {
+	t.Helper()
+	ec := NewClient(rpc.DialInProc(newTestServer(t, map[string]any{"eth": service})), opts...)
+	t.Cleanup(ec.Close)
+	return ec
+}
+
+// newHTTPTestServer serves service as the eth namespace over HTTP and returns the URL. intercept
+// runs before the server if not nil, it answers the request itself by returning true.
+func newHTTPTestServer(t *testing.T, service any, intercept func(w http.ResponseWriter, r *http.Request) bool) string {
+	t.Helper()
+	server := newTestServer(t, map[string]any{"eth": service})
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if intercept != nil && intercept(w, r) {
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
@@ -0,0 +1,398 @@
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/hexutil"
+	"github.com/ethereum/go-ethereum"
+)
+
+// Built-in tracers of the debug namespace.
+const (
+	CallTracer     = "callTracer"
+	PrestateTracer = "prestateTracer"
+	FourByteTracer = "4byteTracer"
+)
+
+// TraceConfig is the config of debug_traceTransaction, debug_traceCall and debug_traceBlock*.
+type TraceConfig struct {
+	Tracer       string `json:"tracer,omitempty"`
+	TracerConfig any    `json:"tracerConfig,omitempty"`
+	// Timeout overrides the default timeout of 5s, e.g. "30s"
+	Timeout string  `json:"timeout,omitempty"`
+	Reexec  *uint64 `json:"reexec,omitempty"`
+}
+
+// CallTracerConfig configures the callTracer.
+type CallTracerConfig struct {
+	// OnlyTopCall skips the sub calls
+	OnlyTopCall bool `json:"onlyTopCall,omitempty"`
+	// WithLog collects the logs emitted by every call
+	WithLog bool `json:"withLog,omitempty"`
+}
+
+// PrestateTracerConfig configures the prestateTracer.
+type PrestateTracerConfig struct {
+	// DiffMode returns the accounts before and after the execution, see StateDiff
+	DiffMode       bool `json:"diffMode,omitempty"`
+	DisableCode    bool `json:"disableCode,omitempty"`
+	DisableStorage bool `json:"disableStorage,omitempty"`
+	IncludeEmpty   bool `json:"includeEmpty,omitempty"`
+}
+
+//go:generate go run github.com/fjl/gencodec -type CallFrame -field-override callFrameMarshaling -out gen_call_frame.go
+
+// CallFrame is a call of the callTracer, Calls are its sub calls in execution order.
+type CallFrame struct {
+	// Type is CALL, STATICCALL, DELEGATECALL, CALLCODE, CREATE, CREATE2 or SELFDESTRUCT
+	Type         string           `json:"type"`
+	From         ecommon.Address  `json:"from"`
+	Gas          uint64           `json:"gas"`
+	GasUsed      uint64           `json:"gasUsed"`
+	To           *ecommon.Address `json:"to,omitempty"`
+	Input        []byte           `json:"input"`
+	Output       []byte           `json:"output,omitempty"`
+	Error        string           `json:"error,omitempty"`
+	RevertReason string           `json:"revertReason,omitempty"`
+	Calls        []CallFrame      `json:"calls,omitempty"`
+	Logs         []CallLog        `json:"logs,omitempty"`
+	Value        *big.Int         `json:"value,omitempty"`
+}
+
+type callFrameMarshaling struct {
+	Gas     hexutil.Uint64
+	GasUsed hexutil.Uint64
+	Input   hexutil.Bytes
+	Output  hexutil.Bytes
+	Value   *hexutil.Big
+}
+
+// CallLog is a log collected by the callTracer with WithLog.
+type CallLog struct {
+	Address ecommon.Address `json:"address"`
+	Topics  []ecommon.Hash  `json:"topics"`
+	Data    hexutil.Bytes   `json:"data"`
+	// Position is the number of sub calls made before the log was emitted
+	Position hexutil.Uint `json:"position"`
+}
+
+// Failed reports whether the call failed (reverted or ran into an error).
+func (f *CallFrame) Failed() bool {
+	return f.Error != ""
+}
+
+// Walk visits f and its sub calls depth first, it stops descending into a frame when fn returns false.
+func (f *CallFrame) Walk(fn func(frame *CallFrame, depth int) bool) {
+	f.walk(fn, 0)
+}
+
+func (f *CallFrame) walk(fn func(frame *CallFrame, depth int) bool, depth int) {
+	if !fn(f, depth) {
+		return
+	}
+	for i := range f.Calls {
+		f.Calls[i].walk(fn, depth+1)
+	}
+}
+
+// ValueTransfer is a transfer of ether made by a call.
+type ValueTransfer struct {
+	Type  string
+	From  ecommon.Address
+	To    ecommon.Address
+	Value *big.Int
+	Depth int
+}
+
+// ValueTransfers returns the ether transfers of the successful calls, including internal ones.
+// Calls below a failed call are reverted and skipped.
+func (f *CallFrame) ValueTransfers() []ValueTransfer {
+	var transfers []ValueTransfer
+	f.Walk(func(frame *CallFrame, depth int) bool {
+		if frame.Failed() {
+			return false
+		}
+		if frame.Value != nil && frame.Value.Sign() > 0 && frame.To != nil && frame.Type != "DELEGATECALL" {
+			transfers = append(transfers, ValueTransfer{Type: frame.Type, From: frame.From, To: *frame.To, Value: frame.Value, Depth: depth})
+		}
+		return true
+	})
+	return transfers
+}
+
+//go:generate go run github.com/fjl/gencodec -type PrestateAccount -field-override prestateAccountMarshaling -out gen_prestate_account.go
+
+// PrestateAccount is an account of the prestateTracer.
+type PrestateAccount struct {
+	Balance  *big.Int                      `json:"balance,omitempty"`
+	Code     []byte                        `json:"code,omitempty"`
+	CodeHash *ecommon.Hash                 `json:"codeHash,omitempty"`
+	Nonce    uint64                        `json:"nonce,omitempty"`
+	Storage  map[ecommon.Hash]ecommon.Hash `json:"storage,omitempty"`
+}
+
+type prestateAccountMarshaling struct {
+	Balance *hexutil.Big
+	Code    hexutil.Bytes
+}
+
+// StateMap is the accounts touched by an execution.
+type StateMap map[ecommon.Address]*PrestateAccount
+
+// StateDiff is the result of the prestateTracer in diff mode. Pre holds the modified fields before
+// and Post after the execution, an account missing from Post was deleted.
+type StateDiff struct {
+	Pre  StateMap `json:"pre"`
+	Post StateMap `json:"post"`
+}
+
+// TxTraceResult is the trace of one transaction of a block.
+type TxTraceResult[T any] struct {
+	TxHash ecommon.Hash `json:"txHash"`
+	Result T            `json:"result"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// TraceTransactionAs replays the transaction with config and decodes the trace as T.
+//
+// RPC: debug_traceTransaction
+func TraceTransactionAs[T any](ctx context.Context, ec *Client, txHash ecommon.Hash, config *TraceConfig) (T, error) {
+	return Call[T](ec, ctx, "debug_traceTransaction", txHash, config)
+}
+
+// TraceCallAs executes msg on top of the given block (nil means latest) with config and decodes
+// the trace as T.
+//
+// RPC: debug_traceCall
+func TraceCallAs[T any](ctx context.Context, ec *Client, msg ethereum.CallMsg, blockNumber *big.Int, config *TraceConfig) (T, error) {
+	return Call[T](ec, ctx, "debug_traceCall", toCallArg(msg), toBlockNumArg(blockNumber), config)
+}
+
+// TraceBlockByNumberAs replays all transactions of the block with config and decodes the traces as T.
+//
+// RPC: debug_traceBlockByNumber
+func TraceBlockByNumberAs[T any](ctx context.Context, ec *Client, number *big.Int, config *TraceConfig) ([]TxTraceResult[T], error) {
+	return Call[[]TxTraceResult[T]](ec, ctx, "debug_traceBlockByNumber", toBlockNumArg(number), config)
+}
+
+// TraceBlockByHashAs is like TraceBlockByNumberAs for the block with the given hash.
+//
+// RPC: debug_traceBlockByHash
+func TraceBlockByHashAs[T any](ctx context.Context, ec *Client, hash ecommon.Hash, config *TraceConfig) ([]TxTraceResult[T], error) {
+	return Call[[]TxTraceResult[T]](ec, ctx, "debug_traceBlockByHash", hash, config)
+}
+
+// CallTraceTransaction returns the call tree of a transaction.
+func (ec *Client) CallTraceTransaction(ctx context.Context, txHash ecommon.Hash, cfg CallTracerConfig) (*CallFrame, error) {
+	return CallNotFound[*CallFrame](ec, ctx, "debug_traceTransaction", txHash, &TraceConfig{Tracer: CallTracer, TracerConfig: cfg})
+}
+
+// CallTraceCall returns the call tree of msg executed on top of the given block (nil means latest).
+func (ec *Client) CallTraceCall(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, cfg CallTracerConfig) (*CallFrame, error) {
+	return CallNotFound[*CallFrame](ec, ctx, "debug_traceCall", toCallArg(msg), toBlockNumArg(blockNumber), &TraceConfig{Tracer: CallTracer, TracerConfig: cfg})
+}
+
+// CallTraceBlockByNumber returns the call trees of all transactions of a block.
+func (ec *Client) CallTraceBlockByNumber(ctx context.Context, number *big.Int, cfg CallTracerConfig) ([]TxTraceResult[*CallFrame], error) {
+	return TraceBlockByNumberAs[*CallFrame](ctx, ec, number, &TraceConfig{Tracer: CallTracer, TracerConfig: cfg})
+}
+
+// PrestateTraceTransaction returns the accounts a transaction touched, as they were before it.
+// cfg.DiffMode is ignored, see StateDiffTraceTransaction.
+func (ec *Client) PrestateTraceTransaction(ctx context.Context, txHash ecommon.Hash, cfg PrestateTracerConfig) (StateMap, error) {
+	cfg.DiffMode = false
+	return TraceTransactionAs[StateMap](ctx, ec, txHash, &TraceConfig{Tracer: PrestateTracer, TracerConfig: cfg})
+}
+
+// PrestateTraceCall returns the accounts msg touches on top of the given block, as they were before it.
+// cfg.DiffMode is ignored, see StateDiffTraceCall.
+func (ec *Client) PrestateTraceCall(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, cfg PrestateTracerConfig) (StateMap, error) {
+	cfg.DiffMode = false
+	return TraceCallAs[StateMap](ctx, ec, msg, blockNumber, &TraceConfig{Tracer: PrestateTracer, TracerConfig: cfg})
+}
+
+// StateDiffTraceTransaction returns the state changes of a transaction.
+func (ec *Client) StateDiffTraceTransaction(ctx context.Context, txHash ecommon.Hash, cfg PrestateTracerConfig) (*StateDiff, error) {
+	cfg.DiffMode = true
+	return CallNotFound[*StateDiff](ec, ctx, "debug_traceTransaction", txHash, &TraceConfig{Tracer: PrestateTracer, TracerConfig: cfg})
+}
+
+// StateDiffTraceCall returns the state changes msg makes on top of the given block.
+func (ec *Client) StateDiffTraceCall(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, cfg PrestateTracerConfig) (*StateDiff, error) {
+	cfg.DiffMode = true
+	return CallNotFound[*StateDiff](ec, ctx, "debug_traceCall", toCallArg(msg), toBlockNumArg(blockNumber), &TraceConfig{Tracer: PrestateTracer, TracerConfig: cfg})
+}
+
+// FourByteTraceTransaction counts the function selectors called by a transaction. The keys are
+// "selector-calldata size", e.g. "0xa9059cbb-64".
+func (ec *Client) FourByteTraceTransaction(ctx context.Context, txHash ecommon.Hash) (map[string]uint64, error) {
+	return TraceTransactionAs[map[string]uint64](ctx, ec, txHash, &TraceConfig{Tracer: FourByteTracer})
+}
+
+// Parity/OpenEthereum style trace namespace, served by Erigon, Nethermind, Reth and others.
+
+// Parity trace types of trace_replayTransaction and trace_call.
+const (
+	ParityTraceTypeTrace     = "trace"
+	ParityTraceTypeStateDiff = "stateDiff"
+	ParityTraceTypeVmTrace   = "vmTrace"
+)
+
+// ParityTrace is a flattened call of the trace namespace, TraceAddress is its path in the call tree.
+type ParityTrace struct {
+	Action              ParityAction  `json:"action"`
+	BlockHash           *ecommon.Hash `json:"blockHash,omitempty"`
+	BlockNumber         *uint64       `json:"blockNumber,omitempty"`
+	Error               string        `json:"error,omitempty"`
+	Result              *ParityResult `json:"result"`
+	Subtraces           int           `json:"subtraces"`
+	TraceAddress        []int         `json:"traceAddress"`
+	TransactionHash     *ecommon.Hash `json:"transactionHash,omitempty"`
+	TransactionPosition *uint64       `json:"transactionPosition,omitempty"`
+	Type                string        `json:"type"`
+}
+
+// ParityAction is the action of a ParityTrace, the fields depend on its type (call, create, suicide, reward).
+type ParityAction struct {
+	CallType      string           `json:"callType,omitempty"`
+	From          *ecommon.Address `json:"from,omitempty"`
+	To            *ecommon.Address `json:"to,omitempty"`
+	Gas           *hexutil.Uint64  `json:"gas,omitempty"`
+	Input         hexutil.Bytes    `json:"input,omitempty"`
+	Init          hexutil.Bytes    `json:"init,omitempty"`
+	Value         *hexutil.Big     `json:"value,omitempty"`
+	Address       *ecommon.Address `json:"address,omitempty"`
+	RefundAddress *ecommon.Address `json:"refundAddress,omitempty"`
+	Balance       *hexutil.Big     `json:"balance,omitempty"`
+	Author        *ecommon.Address `json:"author,omitempty"`
+	RewardType    string           `json:"rewardType,omitempty"`
+}
+
+// ParityResult is the result of a successful ParityTrace.
+type ParityResult struct {
+	GasUsed hexutil.Uint64   `json:"gasUsed"`
+	Output  hexutil.Bytes    `json:"output,omitempty"`
+	Address *ecommon.Address `json:"address,omitempty"`
+	Code    hexutil.Bytes    `json:"code,omitempty"`
+}
+
+// ParityTraceResults is the result of trace_replayTransaction and trace_call.
+type ParityTraceResults struct {
+	Output          hexutil.Bytes   `json:"output"`
+	StateDiff       ParityStateDiff `json:"stateDiff,omitempty"`
+	Trace           []ParityTrace   `json:"trace,omitempty"`
+	VmTrace         json.RawMessage `json:"vmTrace,omitempty"`
+	TransactionHash *ecommon.Hash   `json:"transactionHash,omitempty"`
+}
+
+// ParityStateDiff is the stateDiff of the trace namespace.
+type ParityStateDiff map[ecommon.Address]ParityAccountDiff
+
+// ParityAccountDiff is the change of one account.
+type ParityAccountDiff struct {
+	Balance ParityDiff                  `json:"balance"`
+	Code    ParityDiff                  `json:"code"`
+	Nonce   ParityDiff                  `json:"nonce"`
+	Storage map[ecommon.Hash]ParityDiff `json:"storage"`
+}
+
+// Kinds of a ParityDiff.
+const (
+	ParityDiffSame    = "="
+	ParityDiffBorn    = "+"
+	ParityDiffDied    = "-"
+	ParityDiffChanged = "*"
+)
+
+// ParityDiff is the change of a value: "=" unchanged, "+" created (To), "-" deleted (From)
+// or "*" changed (From and To). From and To are the raw JSON values, e.g. "0x1".
+type ParityDiff struct {
+	Kind string
+	From json.RawMessage
+	To   json.RawMessage
+}
+
+func (d *ParityDiff) UnmarshalJSON(input []byte) error {
+	var same string
+	if err := json.Unmarshal(input, &same); err == nil {
+		if same != ParityDiffSame {
+			return errors.New("invalid state diff: " + same)
+		}
+		*d = ParityDiff{Kind: ParityDiffSame}
+		return nil
+	}
+	var dec map[string]json.RawMessage
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	switch {
+	case dec[ParityDiffBorn] != nil:
+		*d = ParityDiff{Kind: ParityDiffBorn, To: dec[ParityDiffBorn]}
+	case dec[ParityDiffDied] != nil:
+		*d = ParityDiff{Kind: ParityDiffDied, From: dec[ParityDiffDied]}
+	case dec[ParityDiffChanged] != nil:
+		var changed struct {
+			From json.RawMessage `json:"from"`
+			To   json.RawMessage `json:"to"`
+		}
+		if err := json.Unmarshal(dec[ParityDiffChanged], &changed); err != nil {
+			return err
+		}
+		*d = ParityDiff{Kind: ParityDiffChanged, From: changed.From, To: changed.To}
+	default:
+		return errors.New("invalid state diff: " + string(input))
+	}
+	return nil
+}
+
+func (d ParityDiff) MarshalJSON() ([]byte, error) {
+	switch d.Kind {
+	case ParityDiffSame, "":
+		return json.Marshal(ParityDiffSame)
+	case ParityDiffBorn:
+		return json.Marshal(map[string]json.RawMessage{ParityDiffBorn: d.To})
+	case ParityDiffDied:
+		return json.Marshal(map[string]json.RawMessage{ParityDiffDied: d.From})
+	default:
+		return json.Marshal(map[string]any{ParityDiffChanged: map[string]json.RawMessage{"from": d.From, "to": d.To}})
+	}
+}
+
+// ParityTraceTransaction returns the flattened calls of a transaction.
+//
+// RPC: trace_transaction
+func (ec *Client) ParityTraceTransaction(ctx context.Context, txHash ecommon.Hash) ([]ParityTrace, error) {
+	return Call[[]ParityTrace](ec, ctx, "trace_transaction", txHash)
+}
+
+// ParityTraceBlock returns the flattened calls of all transactions of a block, including rewards.
+//
+// RPC: trace_block
+func (ec *Client) ParityTraceBlock(ctx context.Context, number *big.Int) ([]ParityTrace, error) {
+	return Call[[]ParityTrace](ec, ctx, "trace_block", toBlockNumArg(number))
+}
+
+// ParityTraceReplayTransaction replays a transaction, traceTypes defaults to trace and stateDiff.
+//
+// RPC: trace_replayTransaction
+func (ec *Client) ParityTraceReplayTransaction(ctx context.Context, txHash ecommon.Hash, traceTypes ...string) (*ParityTraceResults, error) {
+	if len(traceTypes) == 0 {
+		traceTypes = []string{ParityTraceTypeTrace, ParityTraceTypeStateDiff}
+	}
+	return CallNotFound[*ParityTraceResults](ec, ctx, "trace_replayTransaction", txHash, traceTypes)
+}
+
+// ParityTraceCall executes msg on top of the given block (nil means latest), traceTypes defaults to
+// trace and stateDiff.
+//
+// RPC: trace_call
+func (ec *Client) ParityTraceCall(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, traceTypes ...string) (*ParityTraceResults, error) {
+	if len(traceTypes) == 0 {
+		traceTypes = []string{ParityTraceTypeTrace, ParityTraceTypeStateDiff}
+	}
+	return CallNotFound[*ParityTraceResults](ec, ctx, "trace_call", toCallArg(msg), traceTypes, toBlockNumArg(blockNumber))
+}
@@ -0,0 +1,123 @@
+package ethclient
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type traceDebugService struct{}
+
+func (traceDebugService) TraceTransaction(hash ecommon.Hash, config TraceConfig) (json.RawMessage, error) {
+	switch config.Tracer {
+	case CallTracer:
+		return json.RawMessage(`{"type":"CALL","from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002","gas":"0x5208","gasUsed":"0x5000","input":"0x","value":"0x10","calls":[
+			{"type":"CALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000003","gas":"0x100","gasUsed":"0x10","input":"0x","value":"0x5"},
+			{"type":"CALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000004","gas":"0x100","gasUsed":"0x100","input":"0x","value":"0x1","error":"execution reverted","calls":[
+				{"type":"CALL","from":"0x0000000000000000000000000000000000000004","to":"0x0000000000000000000000000000000000000005","gas":"0x10","gasUsed":"0x1","input":"0x","value":"0x1"}]}]}`), nil
+	case PrestateTracer:
+		var cfg PrestateTracerConfig
+		raw, _ := json.Marshal(config.TracerConfig)
+		_ = json.Unmarshal(raw, &cfg)
+		if cfg.DiffMode {
+			return json.RawMessage(`{"pre":{"0x0000000000000000000000000000000000000001":{"balance":"0x10","nonce":1}},"post":{"0x0000000000000000000000000000000000000001":{"balance":"0x5","nonce":2}}}`), nil
+		}
+		return json.RawMessage(`{"0x0000000000000000000000000000000000000001":{"balance":"0x10","nonce":1,"code":"0x6080","storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000002"}}}`), nil
+	case FourByteTracer:
+		return json.RawMessage(`{"0xa9059cbb-64":2}`), nil
+	}
+	return nil, nil
+}
+
+func (traceDebugService) TraceBlockByNumber(number string, config TraceConfig) json.RawMessage {
+	return json.RawMessage(`[{"txHash":"0x0000000000000000000000000000000000000000000000000000000000000001","result":{"type":"CALL","from":"0x0000000000000000000000000000000000000001","gas":"0x1","gasUsed":"0x1","input":"0x"}},{"txHash":"0x0000000000000000000000000000000000000000000000000000000000000002","error":"timeout"}]`)
+}
+
+type traceParityService struct{}
+
+func (traceParityService) ReplayTransaction(hash ecommon.Hash, traceTypes []string) json.RawMessage {
+	return json.RawMessage(`{"output":"0x","trace":[{"action":{"callType":"call","from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002","gas":"0x5208","input":"0x","value":"0x10"},"result":{"gasUsed":"0x0","output":"0x"},"subtraces":0,"traceAddress":[],"type":"call"}],
+		"stateDiff":{"0x0000000000000000000000000000000000000001":{"balance":{"*":{"from":"0x10","to":"0x0"}},"code":"=","nonce":{"+":"0x1"},"storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":{"-":"0x02"}}}}}`)
+}
+
+func newTraceTestClient(t *testing.T) *Client {
+	t.Helper()
+	server := newTestServer(t, map[string]any{"debug": traceDebugService{}, "trace": traceParityService{}})
+	ec := NewClient(rpc.DialInProc(server))
+	t.Cleanup(ec.Close)
+	return ec
+}
+
+func TestCallTraceTransaction(t *testing.T) {
+	ec := newTraceTestClient(t)
+	frame, err := ec.CallTraceTransaction(t.Context(), ecommon.Hash{1}, CallTracerConfig{WithLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame.Type != "CALL" || frame.Gas != 0x5208 || len(frame.Calls) != 2 || !frame.Calls[1].Failed() {
+		t.Fatalf("unexpected frame %+v", frame)
+	}
+	transfers := frame.ValueTransfers()
+	if len(transfers) != 2 || transfers[1].To != (ecommon.Address{19: 3}) || transfers[1].Value.Int64() != 5 || transfers[1].Depth != 1 {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+
+	results, err := ec.CallTraceBlockByNumber(t.Context(), big.NewInt(1), CallTracerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Result.From != (ecommon.Address{19: 1}) || results[1].Error != "timeout" {
+		t.Fatalf("unexpected block traces %+v", results)
+	}
+}
+
+func TestPrestateTraceTransaction(t *testing.T) {
+	ec := newTraceTestClient(t)
+	account := ecommon.Address{19: 1}
+
+	state, err := ec.PrestateTraceTransaction(t.Context(), ecommon.Hash{1}, PrestateTracerConfig{DiffMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc := state[account]; acc == nil || acc.Balance.Int64() != 0x10 || acc.Nonce != 1 || len(acc.Code) != 2 || len(acc.Storage) != 1 {
+		t.Fatalf("unexpected prestate %+v", state[account])
+	}
+
+	diff, err := ec.StateDiffTraceTransaction(t.Context(), ecommon.Hash{1}, PrestateTracerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Pre[account].Nonce != 1 || diff.Post[account].Nonce != 2 || diff.Post[account].Balance.Int64() != 5 {
+		t.Fatalf("unexpected diff %+v", diff)
+	}
+
+	selectors, err := ec.FourByteTraceTransaction(t.Context(), ecommon.Hash{1})
+	if err != nil || selectors["0xa9059cbb-64"] != 2 {
+		t.Fatalf("unexpected selectors %v (%v)", selectors, err)
+	}
+}
+
+func TestParityTraceReplayTransaction(t *testing.T) {
+	ec := newTraceTestClient(t)
+	res, err := ec.ParityTraceReplayTransaction(t.Context(), ecommon.Hash{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Trace) != 1 || res.Trace[0].Action.CallType != "call" || res.Trace[0].Action.Value.ToInt().Int64() != 0x10 {
+		t.Fatalf("unexpected trace %+v", res.Trace)
+	}
+	acc := res.StateDiff[ecommon.Address{19: 1}]
+	if acc.Balance.Kind != ParityDiffChanged || string(acc.Balance.From) != `"0x10"` || string(acc.Balance.To) != `"0x0"` {
+		t.Fatalf("unexpected balance diff %+v", acc.Balance)
+	}
+	if acc.Code.Kind != ParityDiffSame || acc.Nonce.Kind != ParityDiffBorn || acc.Storage[ecommon.Hash{31: 1}].Kind != ParityDiffDied {
+		t.Fatalf("unexpected account diff %+v", acc)
+	}
+	encoded, err := json.Marshal(acc.Balance)
+	if err != nil || string(encoded) != `{"*":{"from":"0x10","to":"0x0"}}` {
+		t.Fatalf("unexpected encoding %s (%v)", encoded, err)
+	}
+}
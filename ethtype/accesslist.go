@@ -2,6 +2,9 @@ package ethtype
 
 import (
 	"github.com/donutnomad/eths/ecommon"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/samber/lo"
 )
 
 // AccessList is an EIP-2930 access list.
@@ -12,3 +15,16 @@ type AccessTuple struct {
 	Address     ecommon.Address `json:"address"`
 	StorageKeys []ecommon.Hash  `json:"storageKeys"`
 }
+
+func (al AccessList) ToEthAccessList() ethTypes.AccessList {
+	ret := make(ethTypes.AccessList, len(al))
+	for i, tuple := range al {
+		ret[i] = ethTypes.AccessTuple{
+			Address: common.Address(tuple.Address),
+			StorageKeys: lo.Map(tuple.StorageKeys, func(item ecommon.Hash, index int) common.Hash {
+				return common.Hash(item)
+			}),
+		}
+	}
+	return ret
+}
@@ -0,0 +1,196 @@
+package ethtype
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/donutnomad/eths/hexutil"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"github.com/samber/lo"
+)
+
+// ErrBlockMismatch is wrapped by every VerifyError.
+var ErrBlockMismatch = errors.New("block data doesn't match")
+
+// ErrUnsupportedTxType is returned for transaction types go-ethereum doesn't know, such as the
+// deposit transactions (0x7e) of OP Stack chains. Blocks of such chains can't be verified.
+var ErrUnsupportedTxType = errors.New("unsupported transaction type")
+
+// VerifyError reports the item of a block that disagrees with its header or with itself.
+type VerifyError struct {
+	// Item is "transaction", "receipt" or "header"
+	Item string
+	// Index is the index of the transaction or receipt, -1 for the header
+	Index int
+	// Field is the disagreeing field, e.g. "hash", "logsBloom" or "receiptsRoot"
+	Field string
+	Got   string
+	Want  string
+}
+
+func (e *VerifyError) Error() string {
+	item := e.Item
+	if e.Index >= 0 {
+		item = fmt.Sprintf("%s %d", e.Item, e.Index)
+	}
+	return fmt.Sprintf("%s: %s %s is %s, want %s", ErrBlockMismatch, item, e.Field, e.Got, e.Want)
+}
+
+func (e *VerifyError) Unwrap() error {
+	return ErrBlockMismatch
+}
+
+// VerifyBlock runs VerifyTransactions and VerifyReceipts. Only Ethereum L1 transaction types are
+// supported, the blocks of L2s with their own types fail with ErrUnsupportedTxType.
+func VerifyBlock(b *Block, receipts Receipts) error {
+	if err := VerifyTransactions(b); err != nil {
+		return err
+	}
+	return VerifyReceipts(b, receipts)
+}
+
+// VerifyTransactions rebuilds the transactions trie of b and checks it against Header.TxHash. The
+// hash of every transaction is checked first, so a tampered transaction is reported by its index.
+func VerifyTransactions(b *Block) error {
+	txs := make(ethTypes.Transactions, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		ethTx, err := tx.ToEthTx()
+		if err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+		if hash := ecommon.Hash(ethTx.Hash()); hash != tx.Hash {
+			return &VerifyError{Item: "transaction", Index: i, Field: "hash", Got: tx.Hash.Hex(), Want: hash.Hex()}
+		}
+		txs[i] = ethTx
+	}
+	if root := ecommon.Hash(ethTypes.DeriveSha(txs, trie.NewStackTrie(nil))); root != b.TxHash {
+		return &VerifyError{Item: "header", Index: -1, Field: "transactionsRoot", Got: b.TxHash.Hex(), Want: root.Hex()}
+	}
+	return nil
+}
+
+// VerifyReceipts checks receipts (as returned by eth_getBlockReceipts) against the transactions of
+// b, then rebuilds the receipts trie and the logs bloom and checks them against Header.ReceiptHash
+// and Header.Bloom. The bloom of every receipt is recomputed from its logs.
+func VerifyReceipts(b *Block, receipts Receipts) error {
+	if len(receipts) != len(b.Transactions) {
+		return &VerifyError{Item: "header", Index: -1, Field: "receipts", Got: fmt.Sprint(len(receipts)), Want: fmt.Sprint(len(b.Transactions))}
+	}
+	ethReceipts := make(ethTypes.Receipts, len(receipts))
+	for i, r := range receipts {
+		tx := b.Transactions[i]
+		switch {
+		case r.TxHash != tx.Hash:
+			return &VerifyError{Item: "receipt", Index: i, Field: "transactionHash", Got: r.TxHash.Hex(), Want: tx.Hash.Hex()}
+		case r.Type != tx.Type:
+			return &VerifyError{Item: "receipt", Index: i, Field: "type", Got: fmt.Sprint(r.Type), Want: fmt.Sprint(tx.Type)}
+		}
+		created := CreateBloom(r)
+		if created != r.Bloom {
+			return &VerifyError{Item: "receipt", Index: i, Field: "logsBloom", Got: hexutil.Encode(r.Bloom[:]), Want: hexutil.Encode(created[:])}
+		}
+		ethReceipts[i] = r.ToEthReceipt()
+	}
+	if root := ecommon.Hash(ethTypes.DeriveSha(ethReceipts, trie.NewStackTrie(nil))); root != b.ReceiptHash {
+		return &VerifyError{Item: "header", Index: -1, Field: "receiptsRoot", Got: b.ReceiptHash.Hex(), Want: root.Hex()}
+	}
+	if bloom := MergeBloom(receipts); bloom != b.Bloom {
+		return &VerifyError{Item: "header", Index: -1, Field: "logsBloom", Got: hexutil.Encode(b.Bloom[:]), Want: hexutil.Encode(bloom[:])}
+	}
+	return nil
+}
+
+// ToEthTx converts t to a go-ethereum transaction with the same hash. Only the transaction types
+// of go-ethereum are supported, others return ErrUnsupportedTxType.
+func (t *Tx) ToEthTx() (*ethTypes.Transaction, error) {
+	var to *common.Address
+	if t.To != nil {
+		to = (*common.Address)(t.To)
+	}
+	v := t.V
+	if v == nil {
+		v = new(big.Int).SetUint64(t.YParity)
+	}
+	accessList := t.AccessList.ToEthAccessList()
+	switch t.Type {
+	case ethTypes.LegacyTxType:
+		return ethTypes.NewTx(&ethTypes.LegacyTx{
+			Nonce: t.Nonce, GasPrice: t.GasPrice, Gas: t.Gas, To: to, Value: t.Value, Data: t.Input,
+			V: v, R: t.R, S: t.S,
+		}), nil
+	case ethTypes.AccessListTxType:
+		return ethTypes.NewTx(&ethTypes.AccessListTx{
+			ChainID: t.ChainID, Nonce: t.Nonce, GasPrice: t.GasPrice, Gas: t.Gas, To: to, Value: t.Value,
+			Data: t.Input, AccessList: accessList, V: v, R: t.R, S: t.S,
+		}), nil
+	case ethTypes.DynamicFeeTxType:
+		return ethTypes.NewTx(&ethTypes.DynamicFeeTx{
+			ChainID: t.ChainID, Nonce: t.Nonce, GasTipCap: t.MaxPriorityFeePerGas, GasFeeCap: t.MaxFeePerGas,
+			Gas: t.Gas, To: to, Value: t.Value, Data: t.Input, AccessList: accessList, V: v, R: t.R, S: t.S,
+		}), nil
+	case ethTypes.BlobTxType, ethTypes.SetCodeTxType:
+		if to == nil {
+			return nil, fmt.Errorf("type %d transaction without recipient", t.Type)
+		}
+		if t.Type == ethTypes.BlobTxType {
+			return ethTypes.NewTx(&ethTypes.BlobTx{
+				ChainID: toU256(t.ChainID), Nonce: t.Nonce, GasTipCap: toU256(t.MaxPriorityFeePerGas),
+				GasFeeCap: toU256(t.MaxFeePerGas), Gas: t.Gas, To: *to, Value: toU256(t.Value), Data: t.Input,
+				AccessList: accessList, BlobFeeCap: toU256(t.MaxFeePerBlobGas),
+				BlobHashes: lo.Map(t.BlobVersionedHashes, func(item ecommon.Hash, index int) common.Hash {
+					return common.Hash(item)
+				}),
+				V: toU256(v), R: toU256(t.R), S: toU256(t.S),
+			}), nil
+		}
+		return ethTypes.NewTx(&ethTypes.SetCodeTx{
+			ChainID: toU256(t.ChainID), Nonce: t.Nonce, GasTipCap: toU256(t.MaxPriorityFeePerGas),
+			GasFeeCap: toU256(t.MaxFeePerGas), Gas: t.Gas, To: *to, Value: toU256(t.Value), Data: t.Input,
+			AccessList: accessList,
+			AuthList: lo.Map(t.AuthorizationList, func(item SetCodeAuthorization, index int) ethTypes.SetCodeAuthorization {
+				return ethTypes.SetCodeAuthorization{
+					ChainID: item.ChainID, Address: common.Address(item.Address), Nonce: item.Nonce,
+					V: item.V, R: item.R, S: item.S,
+				}
+			}),
+			V: toU256(v), R: toU256(t.R), S: toU256(t.S),
+		}), nil
+	}
+	return nil, fmt.Errorf("%w %#x", ErrUnsupportedTxType, t.Type)
+}
+
+// ToEthReceipt converts r to a go-ethereum receipt.
+func (r *TxReceipt) ToEthReceipt() *ethTypes.Receipt {
+	return &ethTypes.Receipt{
+		Type:              r.Type,
+		PostState:         r.PostState,
+		Status:            r.Status,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Bloom:             ethTypes.Bloom(r.Bloom),
+		Logs: lo.Map(r.Logs, func(item *Log, index int) *ethTypes.Log {
+			return item.ToEthLog()
+		}),
+		TxHash:            common.Hash(r.TxHash),
+		ContractAddress:   common.Address(lo.FromPtr(r.ContractAddress)),
+		GasUsed:           r.GasUsed,
+		EffectiveGasPrice: r.EffectiveGasPrice,
+		BlobGasUsed:       r.BlobGasUsed,
+		BlobGasPrice:      r.BlobGasPrice,
+		BlockHash:         common.Hash(r.BlockHash),
+		BlockNumber:       r.BlockNumber,
+		TransactionIndex:  r.TransactionIndex,
+	}
+}
+
+func toU256(b *big.Int) *uint256.Int {
+	if b == nil {
+		return new(uint256.Int)
+	}
+	u, _ := uint256.FromBig(b)
+	return u
+}
@@ -0,0 +1,120 @@
+package ethtype
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/donutnomad/eths/ecommon"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// convert round-trips v through its JSON encoding, like a block or receipt fetched over RPC.
+func convert[T any](t *testing.T, v any) T {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var out T
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+// newVerifyTestBlock returns a block with a transaction of every type and its receipts.
+func newVerifyTestBlock(t *testing.T) (*Block, Receipts) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := ethTypes.LatestSignerForChainID(big.NewInt(1))
+	to := common.Address{0xaa}
+	auth, err := ethTypes.SignSetCode(key, ethTypes.SetCodeAuthorization{ChainID: *uint256.NewInt(1), Address: to, Nonce: 9})
+	require.NoError(t, err)
+
+	txData := []ethTypes.TxData{
+		&ethTypes.LegacyTx{Nonce: 0, GasPrice: big.NewInt(1e9), Gas: 21000, To: &to, Value: big.NewInt(1)},
+		&ethTypes.AccessListTx{ChainID: big.NewInt(1), Nonce: 1, GasPrice: big.NewInt(1e9), Gas: 30000, To: &to,
+			AccessList: ethTypes.AccessList{{Address: to, StorageKeys: []common.Hash{{1}}}}},
+		&ethTypes.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2e9), Gas: 50000, Data: []byte{0x60, 0x00}},
+		&ethTypes.BlobTx{ChainID: uint256.NewInt(1), Nonce: 3, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2e9), Gas: 21000, To: to,
+			BlobFeeCap: uint256.NewInt(3), BlobHashes: []common.Hash{{0x01, 2}}},
+		&ethTypes.SetCodeTx{ChainID: uint256.NewInt(1), Nonce: 4, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(2e9), Gas: 60000, To: to,
+			AuthList: []ethTypes.SetCodeAuthorization{auth}},
+	}
+	var txs ethTypes.Transactions
+	var receipts ethTypes.Receipts
+	for i, data := range txData {
+		tx, err := ethTypes.SignNewTx(key, signer, data)
+		require.NoError(t, err)
+		txs = append(txs, tx)
+		receipt := &ethTypes.Receipt{Type: tx.Type(), Status: 1, CumulativeGasUsed: uint64(21000 * (i + 1)), TxHash: tx.Hash(), TransactionIndex: uint(i)}
+		if i%2 == 0 {
+			receipt.Logs = []*ethTypes.Log{{Address: to, Topics: []common.Hash{{byte(i)}}, Data: []byte{byte(i)}}}
+		}
+		receipt.Bloom = ethTypes.CreateBloom(receipt)
+		receipts = append(receipts, receipt)
+	}
+
+	block := &Block{Header: Header{
+		TxHash:      ecommon.Hash(ethTypes.DeriveSha(txs, trie.NewStackTrie(nil))),
+		ReceiptHash: ecommon.Hash(ethTypes.DeriveSha(receipts, trie.NewStackTrie(nil))),
+		Bloom:       Bloom(ethTypes.MergeBloom(receipts)),
+	}}
+	block.Transactions = convert[[]*Transaction](t, txs)
+	return block, convert[Receipts](t, receipts)
+}
+
+func TestVerifyBlock(t *testing.T) {
+	block, receipts := newVerifyTestBlock(t)
+	require.NoError(t, VerifyBlock(block, receipts))
+
+	requireMismatch := func(err error, item string, index int, field string) {
+		t.Helper()
+		var verr *VerifyError
+		require.True(t, errors.As(err, &verr), "expected a VerifyError, got %v", err)
+		require.ErrorIs(t, err, ErrBlockMismatch)
+		require.Equal(t, item, verr.Item)
+		require.Equal(t, index, verr.Index)
+		require.Equal(t, field, verr.Field)
+	}
+
+	t.Run("tampered transaction", func(t *testing.T) {
+		block, receipts := newVerifyTestBlock(t)
+		block.Transactions[2].Gas++
+		requireMismatch(VerifyBlock(block, receipts), "transaction", 2, "hash")
+	})
+	t.Run("reordered transactions", func(t *testing.T) {
+		block, _ := newVerifyTestBlock(t)
+		block.Transactions[0], block.Transactions[1] = block.Transactions[1], block.Transactions[0]
+		requireMismatch(VerifyTransactions(block), "header", -1, "transactionsRoot")
+	})
+	t.Run("missing receipt", func(t *testing.T) {
+		block, receipts := newVerifyTestBlock(t)
+		requireMismatch(VerifyReceipts(block, receipts[1:]), "header", -1, "receipts")
+	})
+	t.Run("removed log", func(t *testing.T) {
+		block, receipts := newVerifyTestBlock(t)
+		receipts[4].Logs = nil
+		requireMismatch(VerifyReceipts(block, receipts), "receipt", 4, "logsBloom")
+	})
+	t.Run("tampered receipt", func(t *testing.T) {
+		block, receipts := newVerifyTestBlock(t)
+		receipts[3].Status = 0
+		requireMismatch(VerifyReceipts(block, receipts), "header", -1, "receiptsRoot")
+	})
+	t.Run("tampered bloom", func(t *testing.T) {
+		block, receipts := newVerifyTestBlock(t)
+		block.Bloom[0] ^= 1
+		requireMismatch(VerifyReceipts(block, receipts), "header", -1, "logsBloom")
+	})
+	t.Run("deposit transaction", func(t *testing.T) {
+		block, receipts := newVerifyTestBlock(t)
+		block.Transactions[1].Type = 0x7e
+		err := VerifyBlock(block, receipts)
+		require.ErrorIs(t, err, ErrUnsupportedTxType)
+		require.NotErrorIs(t, err, ErrBlockMismatch)
+	})
+}
@@ -0,0 +1,227 @@
+// Package hdwallet derives ethereum signers from a BIP-39 mnemonic along BIP-32/BIP-44 paths.
+package hdwallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/donutnomad/blockchain-alg/xecdsa"
+	"github.com/donutnomad/eths/contractcall"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+var ErrInvalidKey = errors.New("derived key is invalid, use the next index")
+
+// DefaultBasePath is m/44'/60'/0'/0, the index is appended to it.
+var DefaultBasePath = accounts.DefaultRootDerivationPath
+
+// NewMnemonic generates a random mnemonic, bits is the entropy size: 128 (12 words) to 256 (24 words).
+func NewMnemonic(bits int) (string, error) {
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// Wallet derives keys from a BIP-39 seed. The private keys are only handed out wrapped in signers.
+type Wallet struct {
+	master   extendedKey
+	basePath accounts.DerivationPath
+
+	mu    sync.Mutex
+	cache map[string]extendedKey
+}
+
+// NewWallet validates mnemonic (words and checksum) and derives the seed with passphrase.
+// Accounts are derived under DefaultBasePath.
+func NewWallet(mnemonic string, passphrase string) (*Wallet, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMnemonic, err)
+	}
+	return NewWalletFromSeed(seed)
+}
+
+// NewWalletFromSeed creates a wallet from a BIP-32 seed (16 to 64 bytes).
+func NewWalletFromSeed(seed []byte) (*Wallet, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length %d", len(seed))
+	}
+	master, err := newMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{
+		master:   master,
+		basePath: DefaultBasePath,
+		cache:    make(map[string]extendedKey),
+	}, nil
+}
+
+// WithBasePath returns a wallet sharing the seed that derives accounts under basePath, e.g. m/44'/60'/1'/0.
+func (w *Wallet) WithBasePath(basePath accounts.DerivationPath) *Wallet {
+	return &Wallet{
+		master:   w.master,
+		basePath: append(accounts.DerivationPath{}, basePath...),
+		cache:    make(map[string]extendedKey),
+	}
+}
+
+// BasePath returns the path accounts are derived under.
+func (w *Wallet) BasePath() accounts.DerivationPath {
+	return append(accounts.DerivationPath{}, w.basePath...)
+}
+
+// Path returns the derivation path of account index.
+func (w *Wallet) Path(index uint32) accounts.DerivationPath {
+	return append(w.BasePath(), index)
+}
+
+// Signer returns the signer of account index (basePath/index).
+func (w *Wallet) Signer(index uint32) (*contractcall.EcdsaPrivateKeySigner, error) {
+	return w.SignerAt(w.Path(index))
+}
+
+// SignerAt returns the signer of an arbitrary derivation path.
+func (w *Wallet) SignerAt(path accounts.DerivationPath) (*contractcall.EcdsaPrivateKeySigner, error) {
+	key, err := w.derive(path)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := xecdsa.NewPrivateKeyS256(key.key)
+	if err != nil {
+		return nil, err
+	}
+	return contractcall.NewEcdsaPrivateKeySigner(privateKey), nil
+}
+
+// Signers returns the signers of accounts [start, start+count).
+func (w *Wallet) Signers(start, count uint32) ([]contractcall.ISigner, error) {
+	signers := make([]contractcall.ISigner, 0, count)
+	for i := uint32(0); i < count; i++ {
+		signer, err := w.Signer(start + i)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// Address returns the address of account index.
+func (w *Wallet) Address(index uint32) (common.Address, error) {
+	return w.AddressAt(w.Path(index))
+}
+
+// AddressAt returns the address of an arbitrary derivation path.
+func (w *Wallet) AddressAt(path accounts.DerivationPath) (common.Address, error) {
+	key, err := w.derive(path)
+	if err != nil {
+		return common.Address{}, err
+	}
+	privateKey, err := crypto.ToECDSA(key.key[:])
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
+
+// Addresses returns the addresses of accounts [start, start+count).
+func (w *Wallet) Addresses(start, count uint32) ([]common.Address, error) {
+	addresses := make([]common.Address, 0, count)
+	for i := uint32(0); i < count; i++ {
+		address, err := w.Address(start + i)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// derive walks path from the master key, the parent of the last level is cached so
+// enumerating the accounts of a base path costs one derivation each.
+func (w *Wallet) derive(path accounts.DerivationPath) (extendedKey, error) {
+	if len(path) == 0 {
+		return w.master, nil
+	}
+	parentPath := path[:len(path)-1]
+
+	w.mu.Lock()
+	parent, ok := w.cache[parentPath.String()]
+	w.mu.Unlock()
+	if !ok {
+		parent = w.master
+		for _, index := range parentPath {
+			var err error
+			if parent, err = parent.child(index); err != nil {
+				return extendedKey{}, err
+			}
+		}
+		w.mu.Lock()
+		w.cache[parentPath.String()] = parent
+		w.mu.Unlock()
+	}
+	return parent.child(path[len(path)-1])
+}
+
+// extendedKey is a BIP-32 extended private key.
+type extendedKey struct {
+	key       [32]byte
+	chainCode [32]byte
+}
+
+func newMasterKey(seed []byte) (extendedKey, error) {
+	return splitKey(hmacSHA512([]byte("Bitcoin seed"), seed), nil)
+}
+
+// child derives the child key at index, indexes >= 0x80000000 are hardened.
+func (k extendedKey) child(index uint32) (extendedKey, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0}, k.key[:]...)
+	} else {
+		privateKey, err := crypto.ToECDSA(k.key[:])
+		if err != nil {
+			return extendedKey{}, err
+		}
+		data = crypto.CompressPubkey(&privateKey.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+	return splitKey(hmacSHA512(k.chainCode[:], data), k.key[:])
+}
+
+// splitKey turns I = HMAC-SHA512(...) into a key, adding parent to IL mod n for child keys.
+func splitKey(sum []byte, parent []byte) (extendedKey, error) {
+	n := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return extendedKey{}, ErrInvalidKey
+	}
+	if parent != nil {
+		il.Add(il, new(big.Int).SetBytes(parent)).Mod(il, n)
+	}
+	if il.Sign() == 0 {
+		return extendedKey{}, ErrInvalidKey
+	}
+	var k extendedKey
+	il.FillBytes(k.key[:])
+	copy(k.chainCode[:], sum[32:])
+	return k, nil
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
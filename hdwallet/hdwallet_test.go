@@ -0,0 +1,119 @@
+package hdwallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/donutnomad/eths/contractcall"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+// BIP-32 test vector 1
+func TestDeriveBIP32Vector(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	w, err := NewWalletFromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tt := range tests {
+		path := accounts.DerivationPath{}
+		if tt.path != "m" {
+			path, err = accounts.ParseDerivationPath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		key, err := w.derive(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key.key[:]); got != tt.key {
+			t.Fatalf("%s: expected %s, got %s", tt.path, tt.key, got)
+		}
+	}
+}
+
+func TestWallet(t *testing.T) {
+	w, err := NewWallet(testMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses, err := w.Addresses(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []common.Address{
+		common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"),
+		common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"),
+	}
+	if addresses[0] != want[0] || addresses[1] != want[1] {
+		t.Fatalf("unexpected addresses %v", addresses)
+	}
+	if w.Path(1).String() != "m/44'/60'/0'/0/1" {
+		t.Fatalf("unexpected path %s", w.Path(1))
+	}
+
+	signer, err := w.Signer(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address() != want[1] {
+		t.Fatalf("expected signer %s, got %s", want[1], signer.Address())
+	}
+	to := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	txWrapper, err := contractcall.NewTxBuilder(t.Context(), big.NewInt(1)).
+		SetFrom(signer.Address()).
+		SetTo(to, false).
+		SetNonce(0).
+		SetGasPrice(contractcall.NewGasPrice(big.NewInt(1), big.NewInt(1), big.NewInt(2))).
+		SetGasLimit(21000).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txWrapper.Sign(signer); err != nil {
+		t.Fatal(err)
+	}
+	sender, err := ethTypes.Sender(ethTypes.LatestSignerForChainID(big.NewInt(1)), txWrapper.ToTransaction())
+	if err != nil || sender != want[1] {
+		t.Fatalf("expected sender %s, got %s (%v)", want[1], sender, err)
+	}
+
+	withPassphrase, _ := NewWallet(testMnemonic, "passphrase")
+	if address, _ := withPassphrase.Address(0); address == want[0] {
+		t.Fatal("passphrase must change the derived accounts")
+	}
+	other := w.WithBasePath(accounts.DerivationPath{0x80000000 + 44, 0x80000000 + 60, 0x80000000 + 1, 0})
+	if address, _ := other.Address(0); address == want[0] {
+		t.Fatal("base path must change the derived accounts")
+	}
+}
+
+func TestNewWallet_InvalidMnemonic(t *testing.T) {
+	if _, err := NewWallet(strings.Repeat("test ", 12), ""); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Fatalf("expected ErrInvalidMnemonic, got %v", err)
+	}
+	mnemonic, err := NewMnemonic(128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWallet(mnemonic, ""); err != nil || len(strings.Fields(mnemonic)) != 12 {
+		t.Fatalf("unexpected mnemonic %q (%v)", mnemonic, err)
+	}
+}